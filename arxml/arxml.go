@@ -0,0 +1,452 @@
+// Package arxml imports the CAN networks of AUTOSAR 4 System Description
+// ARXML files.
+package arxml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/ApexCorse/vera"
+)
+
+// element is an ARXML element, kept generic since only a few of the many
+// AUTOSAR elements are read.
+type element struct {
+	name     string
+	text     string
+	children []*element
+}
+
+func (e *element) child(name string) *element {
+	if e == nil {
+		return nil
+	}
+
+	for _, c := range e.children {
+		if c.name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// path returns the element reached through the children with the given
+// names, or nil.
+func (e *element) path(names ...string) *element {
+	for _, name := range names {
+		e = e.child(name)
+	}
+
+	return e
+}
+
+// find returns the elements with the given name among the descendants of
+// e, not looking into the ones found.
+func (e *element) find(name string) []*element {
+	if e == nil {
+		return nil
+	}
+
+	var found []*element
+	for _, c := range e.children {
+		if c.name == name {
+			found = append(found, c)
+			continue
+		}
+		found = append(found, c.find(name)...)
+	}
+
+	return found
+}
+
+func (e *element) first(name string) *element {
+	found := e.find(name)
+	if len(found) == 0 {
+		return nil
+	}
+
+	return found[0]
+}
+
+func (e *element) value(names ...string) string {
+	if c := e.path(names...); c != nil {
+		return c.text
+	}
+
+	return ""
+}
+
+func (e *element) shortName() string {
+	return e.value("SHORT-NAME")
+}
+
+// description returns the first language of the DESC of the element.
+func (e *element) description() string {
+	return e.value("DESC", "L-2")
+}
+
+func decode(r io.Reader) (*element, error) {
+	decoder := xml.NewDecoder(r)
+	root := &element{}
+	stack := []*element{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			e := &element{name: t.Name.Local}
+			parent.children = append(parent.children, e)
+			stack = append(stack, e)
+		case xml.EndElement:
+			parent.text = strings.TrimSpace(parent.text)
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.text += string(t)
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return root, nil
+}
+
+// importer resolves the references of an ARXML file, which are the paths
+// of SHORT-NAMEs from the root, such as /Frames/EngineSpeed.
+type importer struct {
+	elements map[string]*element
+	// framePorts maps the paths of the FRAME-PORTs of ECU instances to
+	// their ECU.
+	framePorts map[string]vera.Node
+}
+
+// index indexes e and its descendants by path, along with the frame ports
+// of ecu, the ECU instance they are in, if any.
+func (im *importer) index(e *element, path string, ecu vera.Node) {
+	if name := e.shortName(); name != "" {
+		path += "/" + name
+		im.elements[path] = e
+	}
+
+	switch e.name {
+	case "ECU-INSTANCE":
+		ecu = vera.Node(e.shortName())
+	case "FRAME-PORT":
+		im.framePorts[path] = ecu
+	}
+
+	for _, c := range e.children {
+		im.index(c, path, ecu)
+	}
+}
+
+// resolve returns the element referred to by the child of e with the given
+// name.
+func (im *importer) resolve(e *element, name string) (*element, error) {
+	ref := e.child(name)
+	if ref == nil {
+		return nil, fmt.Errorf("%s '%s' has no %s", strings.ToLower(e.name), e.shortName(), name)
+	}
+
+	referred, ok := im.elements[ref.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown reference: %s", ref.text)
+	}
+
+	return referred, nil
+}
+
+// Parse reads the CAN frames of every CAN cluster of an ARXML file, with
+// the I-signals of their I-PDUs. ECU instances are the nodes, transmitting
+// the frames of their outgoing frame ports and receiving the signals of
+// their incoming ones. The linear and text table compu-methods of the
+// signals give their factor, offset, range and value descriptions, and the
+// cyclic timings of the I-PDUs the GenMsgCycleTime attribute.
+func Parse(r io.Reader) (*vera.Config, error) {
+	root, err := decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("arxml: %w", err)
+	}
+
+	im := &importer{
+		elements:   make(map[string]*element),
+		framePorts: make(map[string]vera.Node),
+	}
+	im.index(root, "", "")
+
+	config := &vera.Config{}
+	for _, ecu := range root.find("ECU-INSTANCE") {
+		config.Nodes = append(config.Nodes, vera.Node(ecu.shortName()))
+	}
+
+	hasCycleTimes := false
+	for _, cluster := range root.find("CAN-CLUSTER") {
+		for _, triggering := range cluster.find("CAN-FRAME-TRIGGERING") {
+			message, err := im.convertFrame(triggering)
+			if err != nil {
+				return nil, fmt.Errorf("arxml: %w", err)
+			}
+			if message.Attributes != nil {
+				hasCycleTimes = true
+			}
+
+			config.Messages = append(config.Messages, *message)
+		}
+	}
+
+	if hasCycleTimes {
+		config.AttributeDefinitions = append(config.AttributeDefinitions, vera.AttributeDefinition{
+			Name:   "GenMsgCycleTime",
+			Object: vera.MessageAttribute,
+			Type:   vera.AttributeInt,
+		})
+	}
+
+	return config, nil
+}
+
+func (im *importer) convertFrame(triggering *element) (*vera.Message, error) {
+	frame, err := im.resolve(triggering, "FRAME-REF")
+	if err != nil {
+		return nil, err
+	}
+
+	// AUTOSAR integers can be written in decimal, hexadecimal, binary or
+	// octal, with the prefixes of Go.
+	id, err := strconv.ParseUint(triggering.value("IDENTIFIER"), 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("frame '%s' has invalid identifier: %s", frame.shortName(), triggering.value("IDENTIFIER"))
+	}
+
+	length, err := strconv.ParseUint(frame.value("FRAME-LENGTH"), 0, 8)
+	if err != nil {
+		return nil, fmt.Errorf("frame '%s' has invalid length: %s", frame.shortName(), frame.value("FRAME-LENGTH"))
+	}
+
+	message := &vera.Message{
+		Name:        frame.shortName(),
+		ID:          uint32(id),
+		IsExtended:  triggering.value("CAN-ADDRESSING-MODE") == "EXTENDED",
+		DLC:         uint8(length),
+		Transmitter: "Vector__XXX",
+		Comment:     frame.description(),
+	}
+
+	var receivers []vera.Node
+	for _, ref := range triggering.find("FRAME-PORT-REF") {
+		port, ok := im.elements[ref.text]
+		if !ok {
+			return nil, fmt.Errorf("unknown reference: %s", ref.text)
+		}
+
+		ecu := im.framePorts[ref.text]
+		if port.value("COMMUNICATION-DIRECTION") == "OUT" {
+			message.Transmitter = ecu
+		} else {
+			receivers = append(receivers, ecu)
+		}
+	}
+
+	for _, mapping := range frame.find("PDU-TO-FRAME-MAPPING") {
+		pdu, err := im.resolve(mapping, "PDU-REF")
+		if err != nil {
+			return nil, err
+		}
+		if pdu.name != "I-SIGNAL-I-PDU" {
+			return nil, fmt.Errorf("frame '%s' has unsupported %s '%s'", frame.shortName(), strings.ToLower(pdu.name), pdu.shortName())
+		}
+
+		if message.Comment == "" {
+			message.Comment = pdu.description()
+		}
+
+		if period := pdu.first("CYCLIC-TIMING").value("TIME-PERIOD", "VALUE"); period != "" {
+			seconds, err := strconv.ParseFloat(period, 64)
+			if err != nil {
+				return nil, fmt.Errorf("i-pdu '%s' has invalid time period: %s", pdu.shortName(), period)
+			}
+			message.Attributes = vera.Attributes{"GenMsgCycleTime": int64(math.Round(seconds * 1000))}
+		}
+
+		var pduStart uint64
+		if position := mapping.value("START-POSITION"); position != "" {
+			if pduStart, err = strconv.ParseUint(position, 0, 16); err != nil {
+				return nil, fmt.Errorf("i-pdu '%s' has invalid start position: %s", pdu.shortName(), position)
+			}
+		}
+		for _, signalMapping := range pdu.find("I-SIGNAL-TO-I-PDU-MAPPING") {
+			// Mappings of I-signal groups only gather the signals
+			// mapped on their own.
+			if signalMapping.child("I-SIGNAL-REF") == nil {
+				continue
+			}
+
+			signal, err := im.convertSignal(signalMapping, uint16(pduStart))
+			if err != nil {
+				return nil, err
+			}
+			signal.Receivers = receivers
+
+			message.Signals = append(message.Signals, *signal)
+		}
+	}
+
+	return message, nil
+}
+
+func (im *importer) convertSignal(mapping *element, pduStart uint16) (*vera.Signal, error) {
+	iSignal, err := im.resolve(mapping, "I-SIGNAL-REF")
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := strconv.ParseUint(mapping.value("START-POSITION"), 0, 16)
+	if err != nil {
+		return nil, fmt.Errorf("i-signal '%s' has invalid start position: %s", iSignal.shortName(), mapping.value("START-POSITION"))
+	}
+
+	length, err := strconv.ParseUint(iSignal.value("LENGTH"), 0, 8)
+	if err != nil {
+		return nil, fmt.Errorf("i-signal '%s' has invalid length: %s", iSignal.shortName(), iSignal.value("LENGTH"))
+	}
+
+	// The start position of big endian signals is their MSB, as the DBC
+	// start bit.
+	signal := &vera.Signal{
+		Name:     iSignal.shortName(),
+		StartBit: pduStart + uint16(start),
+		Length:   uint8(length),
+		Factor:   1,
+		Comment:  iSignal.description(),
+	}
+	if mapping.value("PACKING-BYTE-ORDER") == "MOST-SIGNIFICANT-BYTE-FIRST" {
+		signal.Endianness = vera.BigEndian
+	}
+
+	// The representation of the I-signal on the network, or else the
+	// physical one of its system signal.
+	props := iSignal.path("NETWORK-REPRESENTATION-PROPS").first("SW-DATA-DEF-PROPS-CONDITIONAL")
+	if systemSignal, err := im.resolve(iSignal, "SYSTEM-SIGNAL-REF"); err == nil {
+		if signal.Comment == "" {
+			signal.Comment = systemSignal.description()
+		}
+		if props == nil {
+			props = systemSignal.path("PHYSICAL-PROPS").first("SW-DATA-DEF-PROPS-CONDITIONAL")
+		}
+	}
+	if props == nil {
+		return signal, nil
+	}
+
+	if baseType, err := im.resolve(props, "BASE-TYPE-REF"); err == nil {
+		switch baseType.value("BASE-TYPE-ENCODING") {
+		case "2C":
+			signal.Signed = true
+		case "IEEE754":
+			signal.ValueType = vera.FloatValue
+			if signal.Length == 64 {
+				signal.ValueType = vera.DoubleValue
+			}
+		}
+	}
+
+	if unit, err := im.resolve(props, "UNIT-REF"); err == nil {
+		signal.Unit = unitName(unit)
+	}
+
+	if compuMethod, err := im.resolve(props, "COMPU-METHOD-REF"); err == nil {
+		if err := im.applyCompuMethod(signal, compuMethod); err != nil {
+			return nil, err
+		}
+	}
+
+	return signal, nil
+}
+
+func unitName(unit *element) string {
+	if name := unit.value("DISPLAY-NAME"); name != "" {
+		return name
+	}
+
+	return unit.shortName()
+}
+
+// applyCompuMethod sets the scaling, range and value descriptions of the
+// signal from the internal to physical scales of a compu-method.
+func (im *importer) applyCompuMethod(signal *vera.Signal, compuMethod *element) error {
+	if signal.Unit == "" {
+		if unit, err := im.resolve(compuMethod, "UNIT-REF"); err == nil {
+			signal.Unit = unitName(unit)
+		}
+	}
+
+	for _, scale := range compuMethod.path("COMPU-INTERNAL-TO-PHYS", "COMPU-SCALES").find("COMPU-SCALE") {
+		if text := scale.value("COMPU-CONST", "VT"); text != "" {
+			raw, err := strconv.ParseInt(scale.value("LOWER-LIMIT"), 0, 64)
+			if err != nil {
+				return fmt.Errorf("compu-method '%s' has invalid text table limit: %s", compuMethod.shortName(), scale.value("LOWER-LIMIT"))
+			}
+			if signal.ValueDescriptions == nil {
+				signal.ValueDescriptions = make(map[int64]string)
+			}
+			signal.ValueDescriptions[raw] = text
+			continue
+		}
+
+		coefficients := scale.child("COMPU-RATIONAL-COEFFS")
+		if coefficients == nil {
+			continue
+		}
+
+		numerator := coefficients.path("COMPU-NUMERATOR").find("V")
+		denominator := coefficients.path("COMPU-DENOMINATOR").find("V")
+		if len(numerator) != 2 || len(denominator) != 1 {
+			return fmt.Errorf("compu-method '%s' is not linear", compuMethod.shortName())
+		}
+
+		var offset, factor, divisor float64
+		var err error
+		for _, v := range []struct {
+			e     *element
+			value *float64
+		}{
+			{numerator[0], &offset},
+			{numerator[1], &factor},
+			{denominator[0], &divisor},
+		} {
+			if *v.value, err = strconv.ParseFloat(v.e.text, 64); err != nil {
+				return fmt.Errorf("compu-method '%s' has invalid coefficient: %s", compuMethod.shortName(), v.e.text)
+			}
+		}
+		if divisor == 0 {
+			return fmt.Errorf("compu-method '%s' has zero denominator", compuMethod.shortName())
+		}
+
+		signal.Factor = float32(factor / divisor)
+		signal.Offset = float32(offset / divisor)
+
+		// The limits are raw values.
+		lower, lowerErr := strconv.ParseFloat(scale.value("LOWER-LIMIT"), 64)
+		upper, upperErr := strconv.ParseFloat(scale.value("UPPER-LIMIT"), 64)
+		if lowerErr == nil && upperErr == nil {
+			low := lower*float64(signal.Factor) + float64(signal.Offset)
+			high := upper*float64(signal.Factor) + float64(signal.Offset)
+			signal.Min, signal.Max = float32(min(low, high)), float32(max(low, high))
+		}
+	}
+
+	return nil
+}
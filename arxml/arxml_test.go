@@ -0,0 +1,75 @@
+package arxml
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ApexCorse/vera"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should import the network of an ARXML file", func(t *testing.T) {
+		a := assert.New(t)
+
+		file, err := os.Open("testdata/network.arxml")
+		a.Nil(err)
+		defer file.Close()
+
+		config, err := vera.ParseWith(file, vera.ParseOptions{File: "network.arxml", Importer: Parse})
+		a.Nil(err)
+		a.Nil(config.Validate())
+
+		expected, err := os.ReadFile("testdata/network.dbc")
+		a.Nil(err)
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+		a.Equal(string(expected), b.String())
+	})
+
+	t.Run("should return error for unsupported PDUs", func(t *testing.T) {
+		a := assert.New(t)
+
+		file, err := os.ReadFile("testdata/network.arxml")
+		a.Nil(err)
+
+		arxml := strings.Replace(string(file), `<PDU-REF DEST="I-SIGNAL-I-PDU">/PDUs/Status</PDU-REF>`, `<PDU-REF DEST="MULTIPLEXED-I-PDU">/PDUs/Mux</PDU-REF>`, 1)
+		arxml = strings.Replace(arxml, "<I-SIGNAL-I-PDU>\n          <SHORT-NAME>Status</SHORT-NAME>", "<MULTIPLEXED-I-PDU>\n          <SHORT-NAME>Mux</SHORT-NAME>\n        </MULTIPLEXED-I-PDU>\n        <I-SIGNAL-I-PDU>\n          <SHORT-NAME>Status</SHORT-NAME>", 1)
+
+		_, err = Parse(strings.NewReader(arxml))
+		a.EqualError(err, "arxml: frame 'Status' has unsupported multiplexed-i-pdu 'Mux'")
+	})
+
+	t.Run("should return error for unknown references", func(t *testing.T) {
+		a := assert.New(t)
+
+		file, err := os.ReadFile("testdata/network.arxml")
+		a.Nil(err)
+
+		arxml := strings.Replace(string(file), "/Signals/Ratio</I-SIGNAL-REF>", "/Signals/Missing</I-SIGNAL-REF>", 1)
+
+		_, err = Parse(strings.NewReader(arxml))
+		a.EqualError(err, "arxml: unknown reference: /Signals/Missing")
+	})
+
+	t.Run("should return error for invalid PDU start positions", func(t *testing.T) {
+		a := assert.New(t)
+
+		file, err := os.ReadFile("testdata/network.arxml")
+		a.Nil(err)
+
+		arxml := strings.Replace(string(file), "/PDUs/Status</PDU-REF>\n              <START-POSITION>0</START-POSITION>", "/PDUs/Status</PDU-REF>\n              <START-POSITION>first</START-POSITION>", 1)
+
+		_, err = Parse(strings.NewReader(arxml))
+		a.EqualError(err, "arxml: i-pdu 'Status' has invalid start position: first")
+	})
+
+	t.Run("should return error for invalid XML", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`<AUTOSAR>`))
+		a.NotNil(err)
+	})
+}
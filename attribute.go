@@ -0,0 +1,524 @@
+package vera
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// AttributeObject is the kind of object an attribute is defined for, named
+// after its DBC keyword.
+type AttributeObject string
+
+const (
+	NetworkAttribute AttributeObject = ""
+	NodeAttribute    AttributeObject = "BU_"
+	MessageAttribute AttributeObject = "BO_"
+	SignalAttribute  AttributeObject = "SG_"
+)
+
+type AttributeType string
+
+const (
+	AttributeInt    AttributeType = "INT"
+	AttributeHex    AttributeType = "HEX"
+	AttributeFloat  AttributeType = "FLOAT"
+	AttributeString AttributeType = "STRING"
+	AttributeEnum   AttributeType = "ENUM"
+)
+
+// AttributeDefinition is a BA_DEF_ attribute definition, with its
+// BA_DEF_DEF_ default.
+type AttributeDefinition struct {
+	Name   string
+	Object AttributeObject
+	Type   AttributeType
+	// Min and Max bound INT, HEX and FLOAT values, unless both are 0.
+	Min float64
+	Max float64
+	// EnumValues are the labels of ENUM values, indexed by value.
+	EnumValues []string
+	// Default is the value of the objects that don't set the attribute,
+	// nil if there is none.
+	Default any
+
+	lineNumber int
+}
+
+// Attributes maps attribute names to their values: int64 for INT and HEX
+// attributes, float64 for FLOAT ones, and string for STRING and ENUM ones,
+// ENUM values being their labels.
+type Attributes map[string]any
+
+// Get returns the value of an attribute, or nil if it is not set. Meant for
+// templates, which can't use the typed lookups.
+func (a Attributes) Get(name string) any {
+	return a[name]
+}
+
+// Int returns the value of an INT or HEX attribute.
+func (a Attributes) Int(name string) (int64, bool) {
+	value, ok := a[name].(int64)
+	return value, ok
+}
+
+// Float returns the value of a FLOAT attribute, or of an INT or HEX one
+// converted to float64.
+func (a Attributes) Float(name string) (float64, bool) {
+	switch value := a[name].(type) {
+	case float64:
+		return value, true
+	case int64:
+		return float64(value), true
+	default:
+		return 0, false
+	}
+}
+
+// String returns the value of a STRING attribute, or the label of the value
+// of an ENUM one.
+func (a Attributes) String(name string) (string, bool) {
+	value, ok := a[name].(string)
+	return value, ok
+}
+
+// AttributeDefinition returns the definition of the named attribute, or nil
+// if there is none.
+func (c *Config) AttributeDefinition(name string) *AttributeDefinition {
+	for i := range c.AttributeDefinitions {
+		if c.AttributeDefinitions[i].Name == name {
+			return &c.AttributeDefinitions[i]
+		}
+	}
+
+	return nil
+}
+
+func (d *AttributeDefinition) Validate() error {
+	if d.Name == "" {
+		return errorAtLine(d.lineNumber, "attribute definition must have a name")
+	}
+
+	switch d.Type {
+	case AttributeInt, AttributeHex, AttributeFloat:
+		if d.Min > d.Max {
+			return errorAtLine(d.lineNumber, "attribute '%s' has min %v greater than max %v", d.Name, d.Min, d.Max)
+		}
+	case AttributeEnum:
+		if len(d.EnumValues) == 0 {
+			return errorAtLine(d.lineNumber, "attribute '%s' has no enum values", d.Name)
+		}
+	case AttributeString:
+	default:
+		return errorAtLine(d.lineNumber, "attribute '%s' has invalid type: %s", d.Name, d.Type)
+	}
+
+	if d.Default != nil {
+		if err := d.checkValue(d.Default); err != nil {
+			return errorAtLine(d.lineNumber, "attribute '%s' has invalid default: %s", d.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// checkValue checks that value has the type and is within the range of the
+// definition.
+func (d *AttributeDefinition) checkValue(value any) error {
+	switch d.Type {
+	case AttributeInt, AttributeHex:
+		v, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("value %v is not an integer", value)
+		}
+		return d.checkRange(float64(v))
+	case AttributeFloat:
+		v, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("value %v is not a float", value)
+		}
+		return d.checkRange(v)
+	case AttributeString:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("value %v is not a string", value)
+		}
+	case AttributeEnum:
+		v, ok := value.(string)
+		if !ok || !slices.Contains(d.EnumValues, v) {
+			return fmt.Errorf("value %v is not one of %s", value, strings.Join(d.EnumValues, ", "))
+		}
+	}
+
+	return nil
+}
+
+func (d *AttributeDefinition) checkRange(value float64) error {
+	if d.Min == 0 && d.Max == 0 {
+		return nil
+	}
+
+	if value < d.Min || value > d.Max {
+		return fmt.Errorf("value %v is out of range [%v, %v]", value, d.Min, d.Max)
+	}
+
+	return nil
+}
+
+// parseValue converts a value as written in BA_ and BA_DEF_DEF_ lines.
+// ENUM values can be written either as their index or as their quoted
+// label.
+func (d *AttributeDefinition) parseValue(valueStr string) (any, error) {
+	switch d.Type {
+	case AttributeInt, AttributeHex:
+		value, err := strconv.ParseInt(valueStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer: %s", valueStr)
+		}
+		return value, nil
+	case AttributeFloat:
+		value, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float: %s", valueStr)
+		}
+		return value, nil
+	case AttributeString:
+		if !isQuoted(valueStr) {
+			return nil, fmt.Errorf("invalid string: %s", valueStr)
+		}
+		return unquote(valueStr), nil
+	case AttributeEnum:
+		if isQuoted(valueStr) {
+			return unquote(valueStr), nil
+		}
+
+		index, err := strconv.Atoi(valueStr)
+		if err != nil || index < 0 || index >= len(d.EnumValues) {
+			return nil, fmt.Errorf("invalid enum value: %s", valueStr)
+		}
+		return d.EnumValues[index], nil
+	}
+
+	return nil, fmt.Errorf("invalid type: %s", d.Type)
+}
+
+// formatValue is the inverse of parseValue.
+func (d *AttributeDefinition) formatValue(value any) string {
+	switch v := value.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		if d.Type == AttributeEnum {
+			if index := slices.Index(d.EnumValues, v); index >= 0 {
+				return strconv.Itoa(index)
+			}
+		}
+		return quote(v)
+	}
+
+	return fmt.Sprint(value)
+}
+
+type objectAttribute struct {
+	name       string
+	object     AttributeObject
+	node       Node
+	messageID  uint32
+	signalName string
+	value      string
+	lineNumber int
+}
+
+type attributeDefault struct {
+	name       string
+	value      string
+	lineNumber int
+}
+
+// parseAttributeDefinition parses a BA_DEF_ statement, returning nil for
+// definitions on objects vera does not know, such as environment variables.
+func parseAttributeDefinition(statement string, lineNumber int) (*AttributeDefinition, error) {
+	statement = strings.TrimSpace(statement)
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+
+	lineParts := splitFields(statement)
+	wrongStructure := errorAtLine(lineNumber, `attribute definition has wrong structure: %s
+Should be:
+	BA_DEF_ [BU_|BO_|SG_] "<AttributeName>" <INT|HEX|FLOAT> <Min> <Max>;
+	BA_DEF_ [BU_|BO_|SG_] "<AttributeName>" STRING;
+	BA_DEF_ [BU_|BO_|SG_] "<AttributeName>" ENUM "<Value>", ...;`, statement)
+
+	if len(lineParts) < 3 {
+		return nil, wrongStructure
+	}
+
+	definition := &AttributeDefinition{
+		lineNumber: lineNumber,
+	}
+
+	lineParts = lineParts[1:]
+	if !isQuoted(lineParts[0]) {
+		switch object := AttributeObject(lineParts[0]); object {
+		case NodeAttribute, MessageAttribute, SignalAttribute:
+			definition.Object = object
+		default:
+			return nil, nil
+		}
+		lineParts = lineParts[1:]
+	}
+
+	if len(lineParts) < 2 || !isQuoted(lineParts[0]) {
+		return nil, wrongStructure
+	}
+	definition.Name = unquote(lineParts[0])
+	definition.Type = AttributeType(lineParts[1])
+	params := lineParts[2:]
+
+	switch definition.Type {
+	case AttributeInt, AttributeHex, AttributeFloat:
+		if len(params) != 2 {
+			return nil, wrongStructure
+		}
+
+		var err error
+		if definition.Min, err = strconv.ParseFloat(params[0], 64); err != nil {
+			return nil, errorAtLine(lineNumber, "attribute definition has invalid min: %s", params[0])
+		}
+		if definition.Max, err = strconv.ParseFloat(params[1], 64); err != nil {
+			return nil, errorAtLine(lineNumber, "attribute definition has invalid max: %s", params[1])
+		}
+	case AttributeString:
+		if len(params) != 0 {
+			return nil, wrongStructure
+		}
+	case AttributeEnum:
+		for _, value := range strings.Split(strings.Join(params, ""), ",") {
+			if !isQuoted(value) {
+				return nil, errorAtLine(lineNumber, "attribute definition has invalid enum value: %s", value)
+			}
+			definition.EnumValues = append(definition.EnumValues, unquote(value))
+		}
+	default:
+		return nil, errorAtLine(lineNumber, "attribute definition has invalid type: %s", definition.Type)
+	}
+
+	return definition, nil
+}
+
+func parseAttributeDefault(statement string, lineNumber int) (*attributeDefault, error) {
+	statement = strings.TrimSpace(statement)
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+
+	lineParts := splitFields(statement)
+	if len(lineParts) != 3 || !isQuoted(lineParts[1]) {
+		return nil, errorAtLine(lineNumber, `attribute default has wrong structure: %s
+Should be:
+	BA_DEF_DEF_ "<AttributeName>" <Value>;`, statement)
+	}
+
+	return &attributeDefault{
+		name:       unquote(lineParts[1]),
+		value:      lineParts[2],
+		lineNumber: lineNumber,
+	}, nil
+}
+
+// parseAttribute parses a BA_ statement, returning nil for attributes of
+// objects vera does not know, such as environment variables.
+func parseAttribute(statement string, lineNumber int) (*objectAttribute, error) {
+	statement = strings.TrimSpace(statement)
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+
+	lineParts := splitFields(statement)
+	wrongStructure := errorAtLine(lineNumber, `attribute has wrong structure: %s
+Should be one of:
+	BA_ "<AttributeName>" <Value>;
+	BA_ "<AttributeName>" BU_ <NodeName> <Value>;
+	BA_ "<AttributeName>" BO_ <MessageID> <Value>;
+	BA_ "<AttributeName>" SG_ <MessageID> <SignalName> <Value>;`, statement)
+
+	if len(lineParts) < 3 || !isQuoted(lineParts[1]) {
+		return nil, wrongStructure
+	}
+
+	attribute := &objectAttribute{
+		name:       unquote(lineParts[1]),
+		value:      lineParts[len(lineParts)-1],
+		lineNumber: lineNumber,
+	}
+
+	if len(lineParts) == 3 {
+		return attribute, nil
+	}
+
+	attribute.object = AttributeObject(lineParts[2])
+	switch {
+	case attribute.object == NodeAttribute && len(lineParts) == 5:
+		attribute.node = Node(lineParts[3])
+	case attribute.object == MessageAttribute && len(lineParts) == 5,
+		attribute.object == SignalAttribute && len(lineParts) == 6:
+		messageID, err := strconv.ParseUint(lineParts[3], 10, 32)
+		if err != nil {
+			return nil, errorAtLine(lineNumber, "attribute has invalid message ID: %s", lineParts[3])
+		}
+		attribute.messageID = uint32(messageID)
+
+		if attribute.object == SignalAttribute {
+			attribute.signalName = lineParts[4]
+		}
+	case attribute.object == NodeAttribute || attribute.object == MessageAttribute || attribute.object == SignalAttribute:
+		return nil, wrongStructure
+	default:
+		return nil, nil
+	}
+
+	return attribute, nil
+}
+
+// inferAttributeValue converts the value of an attribute without
+// definition, which Config.Validate then reports.
+func inferAttributeValue(valueStr string) (any, error) {
+	if isQuoted(valueStr) {
+		return unquote(valueStr), nil
+	}
+	if value, err := strconv.ParseInt(valueStr, 10, 64); err == nil {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value, nil
+	}
+
+	return nil, fmt.Errorf("invalid value: %s", valueStr)
+}
+
+func (c *Config) attachAttributes(definitions []AttributeDefinition, defaults []attributeDefault, attributes []objectAttribute) []error {
+	var errs []error
+	c.AttributeDefinitions = append(c.AttributeDefinitions, definitions...)
+
+	for _, d := range defaults {
+		// Defaults of skipped definitions, such as those of environment
+		// variables, are skipped as well
+		definition := c.AttributeDefinition(d.name)
+		if definition == nil {
+			continue
+		}
+
+		value, err := definition.parseValue(d.value)
+		if err != nil {
+			errs = append(errs, withCode(errorAtLine(d.lineNumber, "attribute default of '%s' has %s", d.name, err.Error()), CodeSyntax))
+			continue
+		}
+		definition.Default = value
+	}
+
+	for _, a := range attributes {
+		var value any
+		var err error
+		if definition := c.AttributeDefinition(a.name); definition != nil {
+			value, err = definition.parseValue(a.value)
+		} else {
+			value, err = inferAttributeValue(a.value)
+		}
+		if err != nil {
+			errs = append(errs, withCode(errorAtLine(a.lineNumber, "attribute '%s' has %s", a.name, err.Error()), CodeSyntax))
+			continue
+		}
+
+		target, err := c.attributesOf(&a)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		target[a.name] = value
+	}
+
+	c.applyAttributeDefaults()
+
+	return errs
+}
+
+// attributesOf returns the attributes of the object an attribute refers to,
+// creating them if needed.
+func (c *Config) attributesOf(a *objectAttribute) (Attributes, error) {
+	switch a.object {
+	case NodeAttribute:
+		if c.NodeAttributes == nil {
+			c.NodeAttributes = make(map[Node]Attributes)
+		}
+		if c.NodeAttributes[a.node] == nil {
+			c.NodeAttributes[a.node] = make(Attributes)
+		}
+		return c.NodeAttributes[a.node], nil
+	case MessageAttribute:
+		message := c.findMessage(a.messageID)
+		if message == nil {
+			return nil, errorAtLine(a.lineNumber, "attribute '%s' refers to unknown message %d", a.name, a.messageID)
+		}
+		if message.Attributes == nil {
+			message.Attributes = make(Attributes)
+		}
+		return message.Attributes, nil
+	case SignalAttribute:
+		signal := c.findSignal(a.messageID, a.signalName)
+		if signal == nil {
+			return nil, errorAtLine(a.lineNumber, "attribute '%s' refers to unknown signal '%s' in message %d", a.name, a.signalName, a.messageID)
+		}
+		if signal.Attributes == nil {
+			signal.Attributes = make(Attributes)
+		}
+		return signal.Attributes, nil
+	default:
+		if c.Attributes == nil {
+			c.Attributes = make(Attributes)
+		}
+		return c.Attributes, nil
+	}
+}
+
+// applyAttributeDefaults sets the default of every attribute on the objects
+// it is defined for that don't set it.
+func (c *Config) applyAttributeDefaults() {
+	for _, d := range c.AttributeDefinitions {
+		if d.Default == nil {
+			continue
+		}
+
+		switch d.Object {
+		case NetworkAttribute:
+			c.Attributes = withDefault(c.Attributes, &d)
+		case NodeAttribute:
+			for _, n := range c.Nodes {
+				if c.NodeAttributes == nil {
+					c.NodeAttributes = make(map[Node]Attributes)
+				}
+				c.NodeAttributes[n] = withDefault(c.NodeAttributes[n], &d)
+			}
+		case MessageAttribute:
+			for i := range c.Messages {
+				c.Messages[i].Attributes = withDefault(c.Messages[i].Attributes, &d)
+			}
+		case SignalAttribute:
+			for i := range c.Messages {
+				for j := range c.Messages[i].Signals {
+					signal := &c.Messages[i].Signals[j]
+					signal.Attributes = withDefault(signal.Attributes, &d)
+				}
+			}
+		}
+	}
+}
+
+func withDefault(attributes Attributes, d *AttributeDefinition) Attributes {
+	if _, ok := attributes[d.Name]; ok {
+		return attributes
+	}
+
+	if attributes == nil {
+		attributes = make(Attributes)
+	}
+	attributes[d.Name] = d.Default
+
+	return attributes
+}
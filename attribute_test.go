@@ -0,0 +1,293 @@
+package vera
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const attributesConfig = `BU_: Engine VCU
+BO_ 123 EngineSpeed: 8 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+	SG_ Gear : 8|4@1+ (1,0) [0|15] "" VCU
+BO_ 124 EngineStatus: 1 Engine
+	SG_ Status : 0|8@1+ (1,0) [0|255] "" VCU
+
+BA_DEF_  "BusType" STRING;
+BA_DEF_ BU_ "NodeLayerModules" STRING;
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_DEF_ BO_ "GenMsgSendType" ENUM  "Cyclic","OnChange", "IfActive";
+BA_DEF_ SG_ "GenSigStartValue" FLOAT -1000 1000;
+BA_DEF_ SG_ "GenSigInactiveValue" HEX 0 0;
+BA_DEF_ EV_ "GenEnvVarPrefix" STRING;
+BA_DEF_DEF_ "BusType" "CAN";
+BA_DEF_DEF_ "GenMsgCycleTime" 100;
+BA_DEF_DEF_ "GenMsgSendType" "Cyclic";
+BA_DEF_DEF_ "GenEnvVarPrefix" "Env";
+BA_ "BusType" "CAN FD";
+BA_ "NodeLayerModules" BU_ Engine "CANoeILNLVector.dll";
+BA_ "GenMsgCycleTime" BO_ 123 10;
+BA_ "GenMsgSendType" BO_ 124 1;
+BA_ "GenSigStartValue" SG_ 123 Speed 12.5;
+BA_ "GenSigInactiveValue" SG_ 123 Gear 15;
+BA_ "GenEnvVarPrefix" EV_ SomeVariable "Ignored";`
+
+func TestParse_WithAttributes(t *testing.T) {
+	t.Run("should parse attribute definitions", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(attributesConfig))
+		a.Nil(err)
+		a.Len(config.AttributeDefinitions, 6)
+
+		cycleTime := config.AttributeDefinition("GenMsgCycleTime")
+		a.NotNil(cycleTime)
+		a.Equal(MessageAttribute, cycleTime.Object)
+		a.Equal(AttributeInt, cycleTime.Type)
+		a.Equal(0.0, cycleTime.Min)
+		a.Equal(10000.0, cycleTime.Max)
+		a.Equal(int64(100), cycleTime.Default)
+
+		sendType := config.AttributeDefinition("GenMsgSendType")
+		a.NotNil(sendType)
+		a.Equal([]string{"Cyclic", "OnChange", "IfActive"}, sendType.EnumValues)
+		a.Equal("Cyclic", sendType.Default)
+
+		a.Equal(NetworkAttribute, config.AttributeDefinition("BusType").Object)
+		a.Nil(config.AttributeDefinition("GenEnvVarPrefix"))
+	})
+
+	t.Run("should resolve values per object", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(attributesConfig))
+		a.Nil(err)
+
+		a.Equal(Attributes{"BusType": "CAN FD"}, config.Attributes)
+		a.Equal(Attributes{"NodeLayerModules": "CANoeILNLVector.dll"}, config.NodeAttributes["Engine"])
+		a.Equal(Attributes{"GenMsgCycleTime": int64(10), "GenMsgSendType": "Cyclic"}, config.Messages[0].Attributes)
+		a.Equal(Attributes{"GenMsgCycleTime": int64(100), "GenMsgSendType": "OnChange"}, config.Messages[1].Attributes)
+		a.Equal(Attributes{"GenSigStartValue": 12.5}, config.Messages[0].Signals[0].Attributes)
+		a.Equal(Attributes{"GenSigInactiveValue": int64(15)}, config.Messages[0].Signals[1].Attributes)
+		a.Nil(config.Messages[1].Signals[0].Attributes)
+	})
+
+	t.Run("should validate", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(attributesConfig))
+		a.Nil(err)
+		a.Nil(config.Validate())
+	})
+
+	t.Run("should return error for attributes of unknown objects", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`BO_ 123 EngineSpeed: 8 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+BA_DEF_ SG_ "GenSigStartValue" INT 0 255;
+BA_ "GenSigStartValue" SG_ 123 Sped 1;`))
+		a.NotNil(err)
+		a.Equal("line 3: attribute 'GenSigStartValue' refers to unknown signal 'Sped' in message 123", err.Error())
+	})
+
+	t.Run("should return error for values not matching their type", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`BO_ 123 EngineSpeed: 8 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_ "GenMsgCycleTime" BO_ 123 "fast";`))
+		a.NotNil(err)
+		a.Equal("line 3: attribute 'GenMsgCycleTime' has invalid integer: \"fast\"", err.Error())
+	})
+}
+
+func TestAttributes(t *testing.T) {
+	attributes := Attributes{
+		"GenMsgCycleTime":  int64(10),
+		"GenSigStartValue": 12.5,
+		"GenMsgSendType":   "Cyclic",
+	}
+
+	t.Run("should return typed values", func(t *testing.T) {
+		a := assert.New(t)
+
+		cycleTime, ok := attributes.Int("GenMsgCycleTime")
+		a.True(ok)
+		a.Equal(int64(10), cycleTime)
+
+		startValue, ok := attributes.Float("GenSigStartValue")
+		a.True(ok)
+		a.Equal(12.5, startValue)
+
+		sendType, ok := attributes.String("GenMsgSendType")
+		a.True(ok)
+		a.Equal("Cyclic", sendType)
+	})
+
+	t.Run("should convert integers to float", func(t *testing.T) {
+		a := assert.New(t)
+
+		cycleTime, ok := attributes.Float("GenMsgCycleTime")
+		a.True(ok)
+		a.Equal(10.0, cycleTime)
+	})
+
+	t.Run("should report missing and mistyped values", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, ok := attributes.Int("Missing")
+		a.False(ok)
+		_, ok = attributes.Int("GenSigStartValue")
+		a.False(ok)
+		_, ok = attributes.String("GenMsgCycleTime")
+		a.False(ok)
+		a.Nil(attributes.Get("Missing"))
+		a.Equal("Cyclic", attributes.Get("GenMsgSendType"))
+	})
+}
+
+func TestConfigValidate_WithAttributes(t *testing.T) {
+	t.Run("should return error for undefined attributes", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BO_ 123 EngineSpeed: 1 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+BA_ "GenMsgCycleTime" BO_ 123 10;`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal("line 0: message 'EngineSpeed' attribute 'GenMsgCycleTime' is not defined in BA_DEF_", err.Error())
+	})
+
+	t.Run("should return error for attributes of another object", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BO_ 123 EngineSpeed: 1 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_ "GenMsgCycleTime" SG_ 123 Speed 10;`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal("line 1: signal 'Speed' attribute 'GenMsgCycleTime' is not defined for this object", err.Error())
+	})
+
+	t.Run("should return an error per invalid attribute", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BO_ 123 EngineSpeed: 1 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+BA_DEF_ SG_ "GenSigStartValue" INT 0 100;
+BA_ "GenMsgCycleTime" BO_ 123 10;
+BA_ "GenSigStartValue" BO_ 123 5;`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal(`line 0: message 'EngineSpeed' attribute 'GenMsgCycleTime' is not defined in BA_DEF_
+line 0: message 'EngineSpeed' attribute 'GenSigStartValue' is not defined for this object`, err.Error())
+	})
+
+	t.Run("should return error for values out of range", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BU_: Engine
+BA_DEF_ BU_ "ILUsed" INT 0 1;
+BA_ "ILUsed" BU_ Engine 2;`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal("node 'Engine' attribute 'ILUsed' has invalid value: value 2 is out of range [0, 1]", err.Error())
+	})
+
+	t.Run("should return error for unknown enum labels", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BA_DEF_ "BusType" ENUM "CAN","CAN FD";
+BA_ "BusType" "LIN";`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal("network attribute 'BusType' has invalid value: value LIN is not one of CAN, CAN FD", err.Error())
+	})
+
+	t.Run("should return error for invalid defaults", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BA_DEF_ BO_ "GenMsgCycleTime" INT 10 100;
+BA_DEF_DEF_ "GenMsgCycleTime" 0;`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal("line 0: attribute 'GenMsgCycleTime' has invalid default: value 0 is out of range [10, 100]", err.Error())
+	})
+
+	t.Run("should not check the range of 0 to 0", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BA_DEF_ "Baudrate" INT 0 0;
+BA_ "Baudrate" 500000;`))
+		a.Nil(err)
+		a.Nil(config.Validate())
+	})
+}
+
+func TestParseAttributeDefinition(t *testing.T) {
+	t.Run("should skip definitions of environment variables", func(t *testing.T) {
+		a := assert.New(t)
+
+		definition, err := parseAttributeDefinition(`BA_DEF_ EV_ "GenEnvVarPrefix" STRING;`, 4)
+		a.Nil(err)
+		a.Nil(definition)
+	})
+
+	t.Run("should return error for missing range", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseAttributeDefinition(`BA_DEF_ BO_ "GenMsgCycleTime" INT;`, 4)
+		a.NotNil(err)
+		a.Contains(err.Error(), "line 4: attribute definition has wrong structure")
+	})
+
+	t.Run("should return error for unknown type", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseAttributeDefinition(`BA_DEF_ BO_ "GenMsgCycleTime" LONG 0 1;`, 4)
+		a.NotNil(err)
+		a.Equal("line 4: attribute definition has invalid type: LONG", err.Error())
+	})
+
+	t.Run("should return error for unquoted enum values", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseAttributeDefinition(`BA_DEF_ BO_ "GenMsgSendType" ENUM "Cyclic",OnChange;`, 4)
+		a.NotNil(err)
+		a.Equal("line 4: attribute definition has invalid enum value: OnChange", err.Error())
+	})
+}
+
+func TestParseAttribute(t *testing.T) {
+	t.Run("should return error for missing signal name", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseAttribute(`BA_ "GenSigStartValue" SG_ 123 1;`, 4)
+		a.NotNil(err)
+		a.Contains(err.Error(), "line 4: attribute has wrong structure")
+	})
+
+	t.Run("should return error for invalid message ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseAttribute(`BA_ "GenMsgCycleTime" BO_ abc 10;`, 4)
+		a.NotNil(err)
+		a.Equal("line 4: attribute has invalid message ID: abc", err.Error())
+	})
+}
@@ -0,0 +1,366 @@
+// Package can decodes and encodes CAN frames described by a vera.Config,
+// with the same bit layout, scaling and clamping as the generated C code.
+package can
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/ApexCorse/vera"
+)
+
+// ExtendedIDFlag is set on IDs passed to Decode to tell extended (29 bits)
+// frames apart from standard (11 bits) ones, as in DBC files.
+const ExtendedIDFlag uint32 = 1 << 31
+
+var (
+	ErrUnknownMessage = errors.New("unknown message")
+	ErrOutOfBounds    = errors.New("out of bounds")
+)
+
+// SignalStatus tells whether a decoded value can be trusted, as
+// vera_signal_status_t does.
+type SignalStatus uint8
+
+const (
+	StatusOK SignalStatus = iota
+	// StatusOutOfRange is set on values clamped to the signal range.
+	StatusOutOfRange
+	// StatusInvalid is set when the raw value is the GenSigInvalidValue of
+	// the signal.
+	StatusInvalid
+	// StatusNotAvailable is set when the raw value is the GenSigSNA of the
+	// signal.
+	StatusNotAvailable
+)
+
+type DecodedSignal struct {
+	Name   string
+	Unit   string
+	Topic  string
+	Value  float64
+	Status SignalStatus
+}
+
+// Decode decodes the signals of the message with the given ID out of data,
+// skipping the ones not selected by their multiplexors. Values are computed
+// in single precision and clamped to the signal range, as vera_decode_can_frame
+// generated with the default clamp range check does.
+func Decode(cfg *vera.Config, id uint32, data []byte) ([]DecodedSignal, error) {
+	msg := findMessage(cfg, id)
+	if msg == nil {
+		return nil, fmt.Errorf("%w: %#x", ErrUnknownMessage, id&^ExtendedIDFlag)
+	}
+
+	decoded := make([]DecodedSignal, 0, len(msg.Signals))
+	for i := range msg.Signals {
+		s := &msg.Signals[i]
+		if !isSignalPresent(msg, s, data) {
+			continue
+		}
+
+		if !signalFitsInPayload(s, len(data)) {
+			return nil, fmt.Errorf("signal '%s': %w", s.Name, ErrOutOfBounds)
+		}
+
+		raw := getPayload(data, s)
+
+		var value float32
+		switch {
+		case s.ValueType == vera.FloatValue:
+			value = math.Float32frombits(uint32(raw))
+		case s.ValueType == vera.DoubleValue:
+			value = float32(math.Float64frombits(raw))
+		case s.Signed:
+			value = float32(signExtend(raw, s.Length))
+		default:
+			value = float32(raw)
+		}
+		// The explicit conversion prevents fusing into a multiply-add, which
+		// would round differently than the C code.
+		value = float32(value*s.Factor) + s.Offset
+		status := signalStatus(s, raw, value)
+		if status == StatusOutOfRange {
+			value = min(max(value, s.Min), s.Max)
+		}
+
+		decoded = append(decoded, DecodedSignal{
+			Name:   s.Name,
+			Unit:   s.Unit,
+			Topic:  s.Topic,
+			Value:  float64(value),
+			Status: status,
+		})
+	}
+
+	return decoded, nil
+}
+
+// Encode builds the payload of msg out of the physical values of its
+// signals, as vera_encode_<message>_phys does. Signals not selected by their
+// multiplexors can be left out of values.
+func Encode(msg *vera.Message, values map[string]float64) ([]byte, error) {
+	for name := range values {
+		if msg.Signal(name) == nil {
+			return nil, fmt.Errorf("message '%s' has no signal '%s'", msg.Name, name)
+		}
+	}
+
+	e := &encoder{
+		message: msg,
+		values:  values,
+		raws:    make(map[string]uint64),
+	}
+
+	data := make([]byte, msg.DLC)
+	for i := range msg.Signals {
+		s := &msg.Signals[i]
+
+		present, err := e.isPresent(s)
+		if err != nil {
+			return nil, err
+		}
+		if !present {
+			continue
+		}
+
+		if !signalFitsInPayload(s, len(data)) {
+			return nil, fmt.Errorf("signal '%s': %w", s.Name, ErrOutOfBounds)
+		}
+
+		raw, err := e.raw(s)
+		if err != nil {
+			return nil, err
+		}
+		insertPayload(data, raw, s)
+	}
+
+	return data, nil
+}
+
+// signalStatus checks the raw and scaled values of a signal.
+func signalStatus(s *vera.Signal, raw uint64, value float32) SignalStatus {
+	if invalid, ok := s.InvalidValue(); ok && raw == invalid {
+		return StatusInvalid
+	}
+	if notAvailable, ok := s.NotAvailableValue(); ok && raw == notAvailable {
+		return StatusNotAvailable
+	}
+
+	if inSignalRange(float64(value), float64(s.Min), float64(s.Max)) {
+		return StatusOK
+	}
+
+	return StatusOutOfRange
+}
+
+func findMessage(cfg *vera.Config, id uint32) *vera.Message {
+	isExtended := id&ExtendedIDFlag != 0
+	id &^= ExtendedIDFlag
+
+	for i := range cfg.Messages {
+		m := &cfg.Messages[i]
+		if m.ID == id && m.IsExtended == isExtended {
+			return m
+		}
+	}
+
+	return nil
+}
+
+func isSignalPresent(msg *vera.Message, s *vera.Signal, data []byte) bool {
+	if !s.IsMultiplexed() {
+		return true
+	}
+
+	multiplexor := msg.Signal(s.Multiplexor)
+	if multiplexor == nil || !isSignalPresent(msg, multiplexor, data) {
+		return false
+	}
+	if !signalFitsInPayload(multiplexor, len(data)) {
+		return false
+	}
+
+	return inRanges(getPayload(data, multiplexor), s.MultiplexValues)
+}
+
+type encoder struct {
+	message *vera.Message
+	values  map[string]float64
+	raws    map[string]uint64
+}
+
+func (e *encoder) isPresent(s *vera.Signal) (bool, error) {
+	if !s.IsMultiplexed() {
+		return true, nil
+	}
+
+	multiplexor := e.message.Signal(s.Multiplexor)
+	if multiplexor == nil {
+		return false, nil
+	}
+
+	present, err := e.isPresent(multiplexor)
+	if err != nil || !present {
+		return false, err
+	}
+
+	raw, err := e.raw(multiplexor)
+	if err != nil {
+		return false, err
+	}
+
+	return inRanges(raw, s.MultiplexValues), nil
+}
+
+func (e *encoder) raw(s *vera.Signal) (uint64, error) {
+	if raw, ok := e.raws[s.Name]; ok {
+		return raw, nil
+	}
+
+	value, ok := e.values[s.Name]
+	if !ok {
+		return 0, fmt.Errorf("missing value for signal '%s'", s.Name)
+	}
+
+	raw, err := physicalToRaw(value, s)
+	if err != nil {
+		return 0, fmt.Errorf("signal '%s': %w", s.Name, err)
+	}
+	e.raws[s.Name] = raw
+
+	return raw, nil
+}
+
+func inRanges(value uint64, ranges []vera.MultiplexRange) bool {
+	for _, r := range ranges {
+		if r.Contains(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inSignalRange tells whether value is within [low|high]. A [0|0] range,
+// as common in DBC files, means the signal has no range.
+func inSignalRange(value, low, high float64) bool {
+	return (low == 0 && high == 0) || (value >= low && value <= high)
+}
+
+// physicalToRaw applies the inverse of factor and offset to value, rounding
+// half away from zero. IEEE signals are not rounded, the raw value being
+// the bits of their scaled value.
+func physicalToRaw(value float64, s *vera.Signal) (uint64, error) {
+	if !inSignalRange(value, asWritten(s.Min), asWritten(s.Max)) {
+		return 0, ErrOutOfBounds
+	}
+
+	scaled := (value - asWritten(s.Offset)) / asWritten(s.Factor)
+
+	switch s.ValueType {
+	case vera.FloatValue:
+		if math.IsInf(float64(float32(scaled)), 0) && !math.IsInf(scaled, 0) {
+			return 0, ErrOutOfBounds
+		}
+		return uint64(math.Float32bits(float32(scaled))), nil
+	case vera.DoubleValue:
+		return math.Float64bits(scaled), nil
+	}
+
+	halfRange := float64(uint64(1) << (s.Length - 1))
+	lower, upper := 0.0, 2*halfRange
+	if s.Signed {
+		lower, upper = -halfRange, halfRange
+	}
+	if !(scaled > lower-0.5 && scaled < upper-0.5) {
+		return 0, ErrOutOfBounds
+	}
+
+	if scaled >= 0 {
+		return uint64(scaled + 0.5), nil
+	}
+	return -uint64(-scaled + 0.5), nil
+}
+
+// asWritten widens f to the double closest to its shortest decimal form,
+// which is how the generated C code gets its constants.
+func asWritten(f float32) float64 {
+	d, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	return d
+}
+
+func nextBitIndex(bitIndex int, endianness vera.Endianness) int {
+	if endianness == vera.LittleEndian {
+		return bitIndex + 1
+	}
+
+	// Motorola signals follow the sawtooth numbering: from bit 0 of a byte
+	// they continue on bit 7 of the following one.
+	if bitIndex%8 == 0 {
+		return bitIndex + 15
+	}
+	return bitIndex - 1
+}
+
+func signalFitsInPayload(s *vera.Signal, payloadLength int) bool {
+	start, length := int(s.StartBit), int(s.Length)
+
+	if s.Endianness == vera.LittleEndian {
+		return start+length <= payloadLength*8
+	}
+
+	bitsInFirstByte := start%8 + 1
+	if length <= bitsInFirstByte {
+		return start/8 < payloadLength
+	}
+
+	return start/8+(length-bitsInFirstByte+7)/8 < payloadLength
+}
+
+func getPayload(data []byte, s *vera.Signal) uint64 {
+	var res uint64
+	bitIndex := int(s.StartBit)
+
+	for i := 0; i < int(s.Length); i++ {
+		bit := uint64(data[bitIndex/8]>>(bitIndex%8)) & 1
+
+		if s.Endianness == vera.LittleEndian {
+			res |= bit << i
+		} else {
+			res |= bit << (int(s.Length) - 1 - i)
+		}
+
+		bitIndex = nextBitIndex(bitIndex, s.Endianness)
+	}
+
+	return res
+}
+
+// Only the lowest Length bits of raw are written, so negative values are
+// packed as two's complement truncated to the signal width.
+func insertPayload(data []byte, raw uint64, s *vera.Signal) {
+	bitIndex := int(s.StartBit)
+
+	for i := 0; i < int(s.Length); i++ {
+		shift := i
+		if s.Endianness == vera.BigEndian {
+			shift = int(s.Length) - 1 - i
+		}
+
+		data[bitIndex/8] |= byte((raw>>shift)&1) << (bitIndex % 8)
+
+		bitIndex = nextBitIndex(bitIndex, s.Endianness)
+	}
+}
+
+func signExtend(raw uint64, length uint8) int64 {
+	if length == 0 {
+		return 0
+	}
+
+	signBit := uint64(1) << (length - 1)
+	return int64((raw ^ signBit) - signBit)
+}
@@ -0,0 +1,350 @@
+package can
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ApexCorse/vera"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `BO_ 123 Message1: 6 Engine
+	SG_ EngineSpeed : 0|32@1+ (0.1,0) [0|8000] "RPM" DriverGateway
+	SG_ BatteryTemperature : 32|12@1+ (1,400) [0|8000] "ºC" DriverGateway
+
+BO_ 124 InverterStatus: 4 Inverter
+	SG_ Torque : 7|16@0+ (1,0) [0|65535] "Nm" DriverGateway
+	SG_ Current : 23|12@0+ (1,0) [0|4095] "A" DriverGateway
+	SG_ Status : 27|4@0+ (1,0) [0|15] "" DriverGateway
+
+BO_ 125 SignedValues: 8 Inverter
+	SG_ Flag : 0|1@1- (1,0) [-1|0] "" DriverGateway
+	SG_ Small : 1|7@1- (1,0) [-64|63] "" DriverGateway
+	SG_ Wide : 8|33@1- (1,0) [-4294967296|4294967295] "" DriverGateway
+
+BO_ 127 CellVoltages: 8 BMS
+	SG_ CellIndex M : 0|8@1+ (1,0) [0|255] "" DriverGateway
+	SG_ Cell0 m0 : 8|16@1+ (0.001,0) [0|65.535] "V" DriverGateway
+	SG_ CellHigh m2 : 8|16@1+ (0.001,0) [0|65.535] "V" DriverGateway
+	SG_ PackVoltage : 24|16@1+ (0.1,0) [0|6553.5] "V" DriverGateway
+
+BO_ 129 ImuData: 8 Engine
+	SG_ AccelX : 0|32@1- (1,0) [-100|100] "m/s2" DriverGateway
+	SG_ Gyro : 32|32@1- (0.5,0) [-1000|1000] "deg/s" DriverGateway
+
+BO_ 130 LoggerTime: 8 Logger
+	SG_ Uptime : 0|64@1- (1,0) [0|1000000] "s" DriverGateway
+
+BO_ 131 CoolantSensors: 2 BMS
+	SG_ CoolantTemperature : 0|8@1+ (1,-40) [-40|125] "ºC" DriverGateway
+	SG_ CoolantFlow : 8|8@1- (1,0) [0|100] "l/min" DriverGateway
+
+BO_ 2147483904 ExtendedFrame: 1 Engine
+	SG_ ExtendedValue : 0|8@1+ (1,0) [0|255] "" DriverGateway
+
+BA_DEF_ SG_ "GenSigInvalidValue" INT 0 0;
+BA_DEF_ SG_ "GenSigSNA" INT 0 0;
+BA_ "GenSigInvalidValue" SG_ 131 CoolantTemperature 254;
+BA_ "GenSigSNA" SG_ 131 CoolantTemperature 255;
+BA_ "GenSigSNA" SG_ 131 CoolantFlow -1;
+
+TP_ EngineSpeed Engine/Metrics/Speed
+
+SIG_VALTYPE_ 129 AccelX : 1;
+SIG_VALTYPE_ 129 Gyro : 1;
+SIG_VALTYPE_ 130 Uptime : 2;
+
+SG_MUL_VAL_ 127 CellHigh CellIndex 2-5, 7-7;`
+
+func parseTestConfig(t *testing.T) *vera.Config {
+	t.Helper()
+
+	config, err := vera.Parse(strings.NewReader(testConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return config
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("should decode, scale and clamp little endian signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		signals, err := Decode(config, 123, []byte{0xf4, 0x7d, 0x00, 0x00, 0xce, 0xe0, 0x64, 0x10})
+		a.Nil(err)
+		a.Len(signals, 2)
+		a.Equal("EngineSpeed", signals[0].Name)
+		a.Equal("RPM", signals[0].Unit)
+		a.Equal("Engine/Metrics/Speed", signals[0].Topic)
+		a.InDelta(3224.4, signals[0].Value, 0.01)
+		a.Equal("BatteryTemperature", signals[1].Name)
+		a.Equal(float64(606), signals[1].Value)
+	})
+
+	t.Run("should decode big endian signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		signals, err := Decode(config, 124, []byte{0x12, 0x34, 0xab, 0xc5})
+		a.Nil(err)
+		a.Len(signals, 3)
+		a.Equal(float64(0x1234), signals[0].Value)
+		a.Equal(float64(0xabc), signals[1].Value)
+		a.Equal(float64(0x5), signals[2].Value)
+	})
+
+	t.Run("should sign extend signed signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		signals, err := Decode(config, 125, []byte{0x81, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00})
+		a.Nil(err)
+		a.Len(signals, 3)
+		a.Equal(float64(-1), signals[0].Value)
+		a.Equal(float64(-64), signals[1].Value)
+		a.Equal(float64(-4294967296), signals[2].Value)
+	})
+
+	t.Run("should only decode signals selected by their multiplexors", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		signals, err := Decode(config, 127, []byte{0x07, 0x80, 0x0d, 0xa0, 0x0f, 0, 0, 0})
+		a.Nil(err)
+		a.Len(signals, 3)
+		a.Equal("CellIndex", signals[0].Name)
+		a.Equal("CellHigh", signals[1].Name)
+		a.InDelta(3.456, signals[1].Value, 0.0001)
+		a.Equal("PackVoltage", signals[2].Name)
+		a.InDelta(400, signals[2].Value, 0.01)
+	})
+
+	t.Run("should tell extended IDs apart", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		signals, err := Decode(config, 0x100|ExtendedIDFlag, []byte{42})
+		a.Nil(err)
+		a.Len(signals, 1)
+		a.Equal("ExtendedValue", signals[0].Name)
+
+		_, err = Decode(config, 0x100, []byte{42})
+		a.True(errors.Is(err, ErrUnknownMessage))
+	})
+
+	t.Run("should decode IEEE float and double signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		// -9.81f and 40.0f
+		signals, err := Decode(config, 129, []byte{0xc3, 0xf5, 0x1c, 0xc1, 0x00, 0x00, 0x20, 0x42})
+		a.Nil(err)
+		a.Equal(float64(float32(-9.81)), signals[0].Value)
+		a.Equal(float64(20), signals[1].Value)
+
+		// 1234.5
+		signals, err = Decode(config, 130, []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x4a, 0x93, 0x40})
+		a.Nil(err)
+		a.Equal(1234.5, signals[0].Value)
+	})
+
+	t.Run("should flag clamped values", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		signals, err := Decode(config, 131, []byte{100, 50})
+		a.Nil(err)
+		a.Equal(StatusOK, signals[0].Status)
+		a.Equal(StatusOK, signals[1].Status)
+
+		signals, err = Decode(config, 131, []byte{200, 0xc8})
+		a.Nil(err)
+		a.Equal(float64(125), signals[0].Value)
+		a.Equal(StatusOutOfRange, signals[0].Status)
+		a.Equal(float64(0), signals[1].Value)
+		a.Equal(StatusOutOfRange, signals[1].Status)
+	})
+
+	t.Run("should flag invalid and not available values", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		signals, err := Decode(config, 131, []byte{254, 0xff})
+		a.Nil(err)
+		a.Equal(StatusInvalid, signals[0].Status)
+		a.Equal(float64(214), signals[0].Value)
+		a.Equal(StatusNotAvailable, signals[1].Status)
+
+		signals, err = Decode(config, 131, []byte{255, 0xfe})
+		a.Nil(err)
+		a.Equal(StatusNotAvailable, signals[0].Status)
+		a.Equal(StatusOutOfRange, signals[1].Status)
+	})
+
+	t.Run("should return error if a signal does not fit in the payload", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		_, err := Decode(config, 124, []byte{0x12, 0x34, 0xab})
+		a.True(errors.Is(err, ErrOutOfBounds))
+	})
+}
+
+func TestEncode(t *testing.T) {
+	t.Run("should encode physical values", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		data, err := Encode(&config.Messages[0], map[string]float64{
+			"EngineSpeed":        3224.46,
+			"BatteryTemperature": 430,
+		})
+		a.Nil(err)
+		a.Equal([]byte{0xf5, 0x7d, 0x00, 0x00, 0x1e, 0x00}, data)
+	})
+
+	t.Run("should encode big endian and signed signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		data, err := Encode(&config.Messages[1], map[string]float64{
+			"Torque":  0x1234,
+			"Current": 0xabc,
+			"Status":  0x5,
+		})
+		a.Nil(err)
+		a.Equal([]byte{0x12, 0x34, 0xab, 0xc5}, data)
+
+		data, err = Encode(&config.Messages[2], map[string]float64{
+			"Flag":  -1,
+			"Small": -63.6,
+			"Wide":  -4294967296,
+		})
+		a.Nil(err)
+		a.Equal([]byte{0x81, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00}, data)
+	})
+
+	t.Run("should only encode signals selected by their multiplexors", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		data, err := Encode(&config.Messages[3], map[string]float64{
+			"CellIndex":   7,
+			"CellHigh":    3.456,
+			"PackVoltage": 400,
+		})
+		a.Nil(err)
+		a.Equal([]byte{0x07, 0x80, 0x0d, 0xa0, 0x0f, 0, 0, 0}, data)
+	})
+
+	t.Run("should return error for out of bounds values", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		_, err := Encode(&config.Messages[0], map[string]float64{
+			"EngineSpeed":        8000.1,
+			"BatteryTemperature": 430,
+		})
+		a.True(errors.Is(err, ErrOutOfBounds))
+
+		// In range, but negative once the offset is removed
+		_, err = Encode(&config.Messages[0], map[string]float64{
+			"EngineSpeed":        0,
+			"BatteryTemperature": 399,
+		})
+		a.True(errors.Is(err, ErrOutOfBounds))
+	})
+
+	t.Run("should return error if a signal does not fit in the payload", func(t *testing.T) {
+		a := assert.New(t)
+
+		msg := &vera.Message{
+			Name: "Short",
+			ID:   1,
+			DLC:  1,
+			Signals: []vera.Signal{
+				{Name: "Beyond", StartBit: 8, Length: 8, Factor: 1},
+			},
+		}
+
+		_, err := Encode(msg, map[string]float64{"Beyond": 1})
+		a.True(errors.Is(err, ErrOutOfBounds))
+	})
+
+	t.Run("should return error for missing or unknown signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		_, err := Encode(&config.Messages[0], map[string]float64{"EngineSpeed": 0})
+		a.NotNil(err)
+
+		_, err = Encode(&config.Messages[0], map[string]float64{
+			"EngineSpeed":        0,
+			"BatteryTemperature": 430,
+			"Unknown":            0,
+		})
+		a.NotNil(err)
+	})
+
+	t.Run("should encode IEEE float and double signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		data, err := Encode(&config.Messages[4], map[string]float64{
+			"AccelX": -9.81,
+			"Gyro":   20,
+		})
+		a.Nil(err)
+		a.Equal([]byte{0xc3, 0xf5, 0x1c, 0xc1, 0x00, 0x00, 0x20, 0x42}, data)
+
+		data, err = Encode(&config.Messages[5], map[string]float64{"Uptime": 1234.5})
+		a.Nil(err)
+		a.Equal([]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x4a, 0x93, 0x40}, data)
+
+		_, err = Encode(&config.Messages[4], map[string]float64{
+			"AccelX": 100.5,
+			"Gyro":   0,
+		})
+		a.True(errors.Is(err, ErrOutOfBounds))
+	})
+
+	t.Run("should round trip with Decode", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t)
+
+		data, err := Encode(&config.Messages[1], map[string]float64{
+			"Torque":  1000,
+			"Current": 20,
+			"Status":  3,
+		})
+		a.Nil(err)
+
+		signals, err := Decode(config, 124, data)
+		a.Nil(err)
+		a.Equal(float64(1000), signals[0].Value)
+		a.Equal(float64(20), signals[1].Value)
+		a.Equal(float64(3), signals[2].Value)
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ApexCorse/vera"
+)
+
+// diff runs the diff subcommand:
+//
+//	vera diff [-format text|json] old.dbc new.dbc
+//
+// It exits with status 1 if any change is breaking.
+func diff(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "Output format: text or json")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		fmt.Println("fatal: need the old and the new DBC files")
+		os.Exit(1)
+	}
+
+	oldConfig := loadDBC(flags.Arg(0))
+	newConfig := loadDBC(flags.Arg(1))
+
+	changes := vera.Diff(oldConfig, newConfig)
+	breaking := 0
+	for _, c := range changes {
+		if c.Breaking {
+			breaking++
+		}
+	}
+
+	switch *format {
+	case "text":
+		for _, c := range changes {
+			compatibility := "compatible"
+			if c.Breaking {
+				compatibility = "breaking"
+			}
+			fmt.Printf("%-10s  %s\n", compatibility, c)
+		}
+		fmt.Printf("%d changes, %d breaking\n", len(changes), breaking)
+	case "json":
+		if changes == nil {
+			changes = []vera.Change{}
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(changes); err != nil {
+			fmt.Println("fatal:", err.Error())
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("fatal: format '%s' not supported\n", *format)
+		os.Exit(1)
+	}
+
+	if breaking > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ApexCorse/vera"
+)
+
+// lint runs the lint subcommand:
+//
+//	vera lint [-config lint.json] [-format text|json|sarif] file.dbc...
+//
+// It exits with status 1 if any problem is an error.
+func lint(args []string) {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	configPath := flags.String("config", "", "JSON file turning lint rules off or changing their severity")
+	format := flags.String("format", "text", "Output format: text, json or sarif")
+	listRules := flags.Bool("rules", false, "List the lint rules")
+	flags.Parse(args)
+
+	if *listRules {
+		for _, rule := range vera.LintRules {
+			fmt.Printf("%-24s %-8s %s\n", rule.ID, rule.Severity, rule.Description)
+		}
+		return
+	}
+
+	var lintConfig *vera.LintConfig
+	if *configPath != "" {
+		configFile, err := os.Open(*configPath)
+		if err != nil {
+			fmt.Println("fatal: error in opening lint config file: ", err.Error())
+			os.Exit(1)
+		}
+
+		lintConfig, err = vera.ParseLintConfig(configFile)
+		configFile.Close()
+		if err != nil {
+			fmt.Println("fatal:", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if flags.NArg() < 1 {
+		fmt.Println("fatal: need DBC files to lint")
+		os.Exit(1)
+	}
+
+	diagnostics := vera.Diagnostics{}
+	for _, dbcFilePath := range flags.Args() {
+		config, problems := parseDBC(dbcFilePath)
+		diagnostics = append(diagnostics, problems...)
+		diagnostics = append(diagnostics, config.Lint(lintConfig)...)
+	}
+
+	switch *format {
+	case "text":
+		for _, d := range diagnostics {
+			fmt.Printf("%s: %s [%s]\n", d.Severity, d.Error(), d.Code)
+		}
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(diagnostics); err != nil {
+			fmt.Println("fatal:", err.Error())
+			os.Exit(1)
+		}
+	case "sarif":
+		if err := vera.WriteSARIF(os.Stdout, diagnostics); err != nil {
+			fmt.Println("fatal:", err.Error())
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("fatal: format '%s' not supported\n", *format)
+		os.Exit(1)
+	}
+
+	if diagnostics.HasErrors() {
+		os.Exit(1)
+	}
+}
@@ -1,21 +1,41 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ApexCorse/vera"
+	"github.com/ApexCorse/vera/arxml"
 	"github.com/ApexCorse/vera/codegen"
 	"github.com/ApexCorse/vera/codegen/autodevkit"
 	"github.com/ApexCorse/vera/codegen/espidf"
 	"github.com/ApexCorse/vera/codegen/stm32hal"
+	"github.com/ApexCorse/vera/kcd"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			lint(os.Args[2:])
+			return
+		case "diff":
+			diff(os.Args[2:])
+			return
+		}
+	}
+
 	version := os.Getenv("VERA_VERSION")
-	dbcFilePath := flag.String("f", "config.dbc", "DBC file relative path")
+	var dbcFilePaths dbcFiles
+	flag.Var(&dbcFilePaths, "f", "DBC, ARXML or KCD file, or DBC directory, relative path, given once per file to merge (default config.dbc)")
 	sdk := flag.String("sdk", "", "SDK to generate the adapters for")
+	node := flag.String("node", "", "Only generate decoders for the messages this node receives and encoders for the ones it transmits")
+	rangeCheck := flag.String("range-check", "clamp", "What decoding does with values out of the signal range: clamp, report or error")
+	singleHeader := flag.Bool("single-header", false, "Generate a single vera.h, with the implementations and the SDK adapter guarded by VERA_IMPLEMENTATION")
 	versionOpt := flag.Bool("v", false, "The current version")
 
 	flag.Parse()
@@ -34,22 +54,19 @@ func main() {
 	sourceFilePath := buildPath + "/vera.c"
 	headerFilePath := buildPath + "/vera.h"
 
-	dbcFile, err := os.Open(*dbcFilePath)
-	if err != nil {
-		fmt.Println("fatal: error in opening dbc file: ", err.Error())
-		os.Exit(1)
+	if len(dbcFilePaths) == 0 {
+		dbcFilePaths = dbcFiles{"config.dbc"}
 	}
-	defer dbcFile.Close()
+	config := loadNetwork(dbcFilePaths)
 
-	config, err := vera.Parse(dbcFile)
-	if err != nil {
-		fmt.Println("fatal:", err.Error())
-		os.Exit(1)
+	opts := codegen.Options{
+		Node:       vera.Node(*node),
+		RangeCheck: codegen.RangeCheck(*rangeCheck),
 	}
 
-	if err := config.Validate(); err != nil {
-		fmt.Println("fatal:", err.Error())
-		os.Exit(1)
+	if *singleHeader {
+		singleHeaderGeneration(headerFilePath, *sdk, config, opts)
+		return
 	}
 
 	sourceFile, err := os.Create(sourceFilePath)
@@ -65,11 +82,11 @@ func main() {
 	}
 	defer headerFile.Close()
 
-	if err = codegen.GenerateHeader(headerFile, config); err != nil {
+	if err = codegen.GenerateHeader(headerFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
-	if err = codegen.GenerateSource(sourceFile, config); err != nil {
+	if err = codegen.GenerateSource(sourceFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
@@ -77,11 +94,11 @@ func main() {
 	switch *sdk {
 	case "autodevkit":
 		// can throw
-		autodevkitGeneration(buildPath, config)
+		autodevkitGeneration(buildPath, config, opts)
 	case "espidf":
-		espidfGeneration(buildPath, config)
+		espidfGeneration(buildPath, config, opts)
 	case "stm32hal":
-		stm32halGeneration(buildPath, config)
+		stm32halGeneration(buildPath, config, opts)
 	case "":
 	default:
 		fmt.Printf("fatal: sdk '%s' not supported\n", *sdk)
@@ -89,7 +106,161 @@ func main() {
 	}
 }
 
-func autodevkitGeneration(buildPath string, config *vera.Config) {
+// importers read the network descriptions other than DBC files, by
+// extension.
+var importers = map[string]vera.Importer{
+	".arxml": arxml.Parse,
+	".kcd":   kcd.Parse,
+}
+
+// parseDBC parses and validates a DBC file, or a network description one of
+// the importers reads, returning every problem found.
+func parseDBC(dbcFilePath string) (*vera.Config, vera.Diagnostics) {
+	dbcFile, err := os.Open(dbcFilePath)
+	if err != nil {
+		fmt.Println("fatal: error in opening dbc file: ", err.Error())
+		os.Exit(1)
+	}
+	defer dbcFile.Close()
+
+	config, err := vera.ParseWith(dbcFile, vera.ParseOptions{
+		File:      dbcFilePath,
+		AllErrors: true,
+		Importer:  importers[strings.ToLower(filepath.Ext(dbcFilePath))],
+	})
+	var diagnostics vera.Diagnostics
+	if err != nil && !errors.As(err, &diagnostics) {
+		fmt.Println("fatal:", err.Error())
+		os.Exit(1)
+	}
+
+	if err := config.Validate(); err != nil {
+		var validationDiagnostics vera.Diagnostics
+		errors.As(err, &validationDiagnostics)
+		diagnostics = append(diagnostics, validationDiagnostics...)
+	}
+
+	return config, diagnostics
+}
+
+// dbcFiles is the -f flag, which can be given several times.
+type dbcFiles []string
+
+func (f *dbcFiles) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *dbcFiles) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+// loadNetwork loads the DBC files, and the ones in the directories, given
+// with -f, merging them into one network.
+func loadNetwork(paths []string) *vera.Config {
+	var dbcFilePaths []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Println("fatal: error in opening dbc file: ", err.Error())
+			os.Exit(1)
+		}
+		if !info.IsDir() {
+			dbcFilePaths = append(dbcFilePaths, path)
+			continue
+		}
+
+		dirFilePaths, err := filepath.Glob(filepath.Join(path, "*.dbc"))
+		if err != nil {
+			fmt.Println("fatal:", err.Error())
+			os.Exit(1)
+		}
+		if len(dirFilePaths) == 0 {
+			fmt.Printf("fatal: no dbc files in %s\n", path)
+			os.Exit(1)
+		}
+		dbcFilePaths = append(dbcFilePaths, dirFilePaths...)
+	}
+
+	if len(dbcFilePaths) == 1 {
+		return loadDBC(dbcFilePaths[0])
+	}
+
+	// Nodes can be declared by any of the files, which vera.Merge checks.
+	var diagnostics vera.Diagnostics
+	configs := make([]*vera.Config, 0, len(dbcFilePaths))
+	for _, dbcFilePath := range dbcFilePaths {
+		config, problems := parseDBC(dbcFilePath)
+		for _, d := range problems {
+			if d.Code != vera.CodeUndeclaredNode {
+				diagnostics = append(diagnostics, d)
+			}
+		}
+		configs = append(configs, config)
+	}
+
+	config, err := vera.Merge(configs...)
+	var mergeDiagnostics vera.Diagnostics
+	if err != nil && !errors.As(err, &mergeDiagnostics) {
+		fmt.Println("fatal:", err.Error())
+		os.Exit(1)
+	}
+	diagnostics = append(diagnostics, mergeDiagnostics...)
+	if len(diagnostics) > 0 {
+		for _, d := range diagnostics {
+			fmt.Printf("%s: %s\n", d.Severity, d.Error())
+		}
+		fmt.Printf("fatal: %d problems merging %d dbc files\n", len(diagnostics), len(dbcFilePaths))
+		os.Exit(1)
+	}
+
+	return config
+}
+
+// loadDBC parses and validates a DBC file, exiting with its problems if
+// there are any.
+func loadDBC(dbcFilePath string) *vera.Config {
+	config, diagnostics := parseDBC(dbcFilePath)
+	if len(diagnostics) > 0 {
+		for _, d := range diagnostics {
+			fmt.Printf("%s: %s\n", d.Severity, d.Error())
+		}
+		fmt.Printf("fatal: %d problems in %s\n", len(diagnostics), dbcFilePath)
+		os.Exit(1)
+	}
+
+	return config
+}
+
+func singleHeaderGeneration(headerFilePath string, sdk string, config *vera.Config, opts codegen.Options) {
+	var adapters []codegen.Adapter
+	switch sdk {
+	case "autodevkit":
+		adapters = append(adapters, codegen.Adapter{Header: autodevkit.GenerateHeader, Source: autodevkit.GenerateSource})
+	case "espidf":
+		adapters = append(adapters, codegen.Adapter{Header: espidf.GenerateHeader, Source: espidf.GenerateSource})
+	case "stm32hal":
+		adapters = append(adapters, codegen.Adapter{Header: stm32hal.GenerateHeader, Source: stm32hal.GenerateSource})
+	case "":
+	default:
+		fmt.Printf("fatal: sdk '%s' not supported\n", sdk)
+		os.Exit(1)
+	}
+
+	headerFile, err := os.Create(headerFilePath)
+	if err != nil {
+		fmt.Println("fatal:", err.Error())
+		os.Exit(1)
+	}
+	defer headerFile.Close()
+
+	if err := codegen.GenerateSingleHeader(headerFile, config, opts, adapters...); err != nil {
+		fmt.Println("fatal:", err.Error())
+		os.Exit(1)
+	}
+}
+
+func autodevkitGeneration(buildPath string, config *vera.Config, opts codegen.Options) {
 	autodevkitSourceFilePath := buildPath + "/vera_autodevkit.c"
 	autodevkitHeaderFilePath := buildPath + "/vera_autodevkit.h"
 
@@ -107,18 +278,18 @@ func autodevkitGeneration(buildPath string, config *vera.Config) {
 	}
 	defer autodevkitHeaderFile.Close()
 
-	if err := autodevkit.GenerateSource(autodevkitSourceFile, config); err != nil {
+	if err := autodevkit.GenerateSource(autodevkitSourceFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
 
-	if err := autodevkit.GenerateHeader(autodevkitHeaderFile, config); err != nil {
+	if err := autodevkit.GenerateHeader(autodevkitHeaderFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
 }
 
-func stm32halGeneration(buildPath string, config *vera.Config) {
+func stm32halGeneration(buildPath string, config *vera.Config, opts codegen.Options) {
 	stm32halSourceFilePath := buildPath + "/vera_stm32hal.c"
 	stm32halHeaderFilePath := buildPath + "/vera_stm32hal.h"
 
@@ -136,18 +307,18 @@ func stm32halGeneration(buildPath string, config *vera.Config) {
 	}
 	defer stm32halHeaderFile.Close()
 
-	if err := stm32hal.GenerateSource(stm32halSourceFile, config); err != nil {
+	if err := stm32hal.GenerateSource(stm32halSourceFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
 
-	if err := stm32hal.GenerateHeader(stm32halHeaderFile, config); err != nil {
+	if err := stm32hal.GenerateHeader(stm32halHeaderFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
 }
 
-func espidfGeneration(buildPath string, config *vera.Config) {
+func espidfGeneration(buildPath string, config *vera.Config, opts codegen.Options) {
 	espidfSourceFilePath := buildPath + "/vera_espidf.c"
 	espidfHeaderFilePath := buildPath + "/vera_espidf.h"
 
@@ -165,12 +336,12 @@ func espidfGeneration(buildPath string, config *vera.Config) {
 	}
 	defer espidfHeaderFile.Close()
 
-	if err := espidf.GenerateSource(espidfSourceFile, config); err != nil {
+	if err := espidf.GenerateSource(espidfSourceFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
 
-	if err := espidf.GenerateHeader(espidfHeaderFile, config); err != nil {
+	if err := espidf.GenerateHeader(espidfHeaderFile, config, opts); err != nil {
 		fmt.Println("fatal:", err.Error())
 		os.Exit(1)
 	}
@@ -6,41 +6,52 @@ import (
 	"text/template"
 
 	"github.com/ApexCorse/vera"
+	"github.com/ApexCorse/vera/codegen"
 )
 
 //go:embed *.tmpl
 var templateFiles embed.FS
 
-func GenerateHeader(w io.Writer, config *vera.Config) error {
+func GenerateHeader(w io.Writer, config *vera.Config, opts codegen.Options) error {
+	data, err := codegen.NewData(config, opts)
+	if err != nil {
+		return err
+	}
+
 	headerTemplateContent, err := templateFiles.ReadFile("vera_autodevkit.h.tmpl")
 	if err != nil {
 		return err
 	}
 
-	headerTmpl, err := template.New("vera_autodevkit.h").Parse(string(headerTemplateContent))
+	headerTmpl, err := template.New("vera_autodevkit.h").Funcs(codegen.TemplateFuncs).Parse(string(headerTemplateContent))
 	if err != nil {
 		return err
 	}
 
-	if err := headerTmpl.Execute(w, config); err != nil {
+	if err := headerTmpl.Execute(w, data); err != nil {
 		return nil
 	}
 
 	return nil
 }
 
-func GenerateSource(w io.Writer, config *vera.Config) error {
+func GenerateSource(w io.Writer, config *vera.Config, opts codegen.Options) error {
+	data, err := codegen.NewData(config, opts)
+	if err != nil {
+		return err
+	}
+
 	sourceTemplateContent, err := templateFiles.ReadFile("vera_autodevkit.c.tmpl")
 	if err != nil {
 		return err
 	}
 
-	sourceTmpl, err := template.New("vera_autodevkit.c").Parse(string(sourceTemplateContent))
+	sourceTmpl, err := template.New("vera_autodevkit.c").Funcs(codegen.TemplateFuncs).Parse(string(sourceTemplateContent))
 	if err != nil {
 		return err
 	}
 
-	if err := sourceTmpl.Execute(w, config); err != nil {
+	if err := sourceTmpl.Execute(w, data); err != nil {
 		return nil
 	}
 
@@ -2,7 +2,10 @@ package codegen
 
 import (
 	"embed"
+	"fmt"
 	"io"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/ApexCorse/vera"
@@ -11,38 +14,515 @@ import (
 //go:embed *.tmpl
 var templateFiles embed.FS
 
-func GenerateHeader(w io.Writer, config *vera.Config) error {
+// TemplateFuncs are the helpers available to the vera templates. They
+// are shared with the SDK adapters.
+var TemplateFuncs = template.FuncMap{
+	"valueDescriptions":  valueDescriptions,
+	"multiplexorIndex":   multiplexorIndex,
+	"multiplexCondition": multiplexCondition,
+	"multiplexOrder":     multiplexOrder,
+	"cType":              cType,
+	"paramType":          paramType,
+	"toBits":             toBits,
+	"fromBits":           fromBits,
+	"rawPayload":         rawPayload,
+	"invalidValue":       invalidValue,
+	"notAvailableValue":  notAvailableValue,
+	"docComment":         docComment,
+	"paramDocs":          paramDocs,
+	"cString":            cString,
+}
+
+type valueDescription struct {
+	Enumerator  string
+	Value       int64
+	Description string
+}
+
+// valueDescriptions returns the VAL_ descriptions of a signal sorted by
+// value, each with a unique C enumerator name. Enumerators are prefixed
+// with the message name, as signals of different messages can share a
+// name.
+func valueDescriptions(message vera.Message, signal vera.Signal) []valueDescription {
+	values := make([]int64, 0, len(signal.ValueDescriptions))
+	for v := range signal.ValueDescriptions {
+		values = append(values, v)
+	}
+	slices.Sort(values)
+
+	res := make([]valueDescription, 0, len(values))
+	used := make(map[string]bool)
+	for _, v := range values {
+		description := signal.ValueDescriptions[v]
+
+		enumerator := fmt.Sprintf("vera_%s_%s_%s", message.Name, signal.Name, cIdentifier(description))
+		if used[enumerator] {
+			enumerator = cIdentifier(fmt.Sprintf("%s_%d", enumerator, v))
+		}
+		used[enumerator] = true
+
+		res = append(res, valueDescription{
+			Enumerator:  enumerator,
+			Value:       v,
+			Description: description,
+		})
+	}
+
+	return res
+}
+
+// multiplexorIndex returns the index of the signal's multiplexor in the
+// message, or -1 if the signal is not multiplexed.
+func multiplexorIndex(message vera.Message, signal vera.Signal) int {
+	if !signal.IsMultiplexed() {
+		return -1
+	}
+
+	for i, s := range message.Signals {
+		if s.Name == signal.Multiplexor {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// multiplexCondition returns the C condition under which a multiplexed
+// signal is present in the frame. Multiplexor values are read from the
+// variables named after them, prefixed by prefix.
+func multiplexCondition(message vera.Message, signal vera.Signal, prefix string) string {
+	var groups [][]string
+
+	for current := &signal; current != nil && current.IsMultiplexed(); current = message.Signal(current.Multiplexor) {
+		var alternatives []string
+		for _, r := range current.MultiplexValues {
+			if r.Min == r.Max {
+				alternatives = append(alternatives, fmt.Sprintf("%s%s == %d", prefix, current.Multiplexor, r.Min))
+			} else {
+				alternatives = append(alternatives, fmt.Sprintf("(%s%s >= %d && %s%s <= %d)", prefix, current.Multiplexor, r.Min, prefix, current.Multiplexor, r.Max))
+			}
+		}
+
+		groups = append(groups, alternatives)
+	}
+
+	conditions := make([]string, 0, len(groups))
+	for _, alternatives := range groups {
+		condition := strings.Join(alternatives, " || ")
+		if len(groups) > 1 && len(alternatives) > 1 {
+			condition = "(" + condition + ")"
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return strings.Join(conditions, " && ")
+}
+
+// multiplexOrder returns the signals of a message sorted so that every
+// multiplexor comes before the signals it selects.
+func multiplexOrder(message vera.Message) []vera.Signal {
+	depth := func(signal vera.Signal) int {
+		d := 0
+		for current := &signal; current != nil && current.IsMultiplexed() && d <= len(message.Signals); current = message.Signal(current.Multiplexor) {
+			d++
+		}
+		return d
+	}
+
+	signals := slices.Clone(message.Signals)
+	slices.SortStableFunc(signals, func(s1, s2 vera.Signal) int {
+		return depth(s1) - depth(s2)
+	})
+
+	return signals
+}
+
+// cType returns the C type holding the raw value of a signal: float or
+// double for IEEE signals, the smallest fitting integer type otherwise.
+func cType(signal vera.Signal) string {
+	switch signal.ValueType {
+	case vera.FloatValue:
+		return "float"
+	case vera.DoubleValue:
+		return "double"
+	}
+
+	bits := 8
+	for bits < int(signal.Length) {
+		bits *= 2
+	}
+
+	if signal.Signed {
+		return fmt.Sprintf("int%d_t", bits)
+	}
+	return fmt.Sprintf("uint%d_t", bits)
+}
+
+// paramType returns the C type encode functions take the raw value of a
+// signal as.
+func paramType(signal vera.Signal) string {
+	switch {
+	case signal.ValueType != vera.IntegerValue:
+		return cType(signal)
+	case signal.Signed:
+		return "int64_t"
+	default:
+		return "uint64_t"
+	}
+}
+
+// toBits returns the C expression converting the raw value expr of a
+// signal to the bits inserted in the payload.
+func toBits(signal vera.Signal, expr string) string {
+	switch signal.ValueType {
+	case vera.FloatValue:
+		return fmt.Sprintf("_float_to_bits(%s)", expr)
+	case vera.DoubleValue:
+		return fmt.Sprintf("_double_to_bits(%s)", expr)
+	default:
+		return fmt.Sprintf("(uint64_t)%s", expr)
+	}
+}
+
+// fromBits is the inverse of toBits, returning a value of the signal
+// cType.
+func fromBits(signal vera.Signal, expr string) string {
+	switch signal.ValueType {
+	case vera.FloatValue:
+		return fmt.Sprintf("_bits_to_float(%s)", expr)
+	case vera.DoubleValue:
+		return fmt.Sprintf("_bits_to_double(%s)", expr)
+	default:
+		return fmt.Sprintf("(%s)%s", cType(signal), expr)
+	}
+}
+
+// rawPayload returns the C expression reading the bits of a signal out of
+// the payload data, sign extended for signed integer signals.
+func rawPayload(signal vera.Signal, data string) string {
+	expr := fmt.Sprintf("_get_payload_by_start_and_length(%s, %d, %d, %d)", data, signal.StartBit, signal.Length, signal.Endianness)
+	if signal.Signed && signal.ValueType == vera.IntegerValue {
+		expr = fmt.Sprintf("_sign_extend(%s, %d)", expr, signal.Length)
+	}
+
+	return expr
+}
+
+// invalidValue returns the C literal of the raw value flagging a signal as
+// invalid, or an empty string if it has none.
+func invalidValue(signal vera.Signal) string {
+	raw, ok := signal.InvalidValue()
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%#x", raw)
+}
+
+// notAvailableValue returns the C literal of the raw value flagging a
+// signal as not available, or an empty string if it has none.
+func notAvailableValue(signal vera.Signal) string {
+	raw, ok := signal.NotAvailableValue()
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%#x", raw)
+}
+
+// docComment returns a Doxygen comment block, followed by a newline, made
+// of the non-empty paragraphs. It returns an empty string if there are none.
+func docComment(paragraphs ...string) string {
+	var lines []string
+	for _, p := range paragraphs {
+		if p == "" {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, " *")
+		}
+
+		// A '*/' in the text would end the comment early
+		p = strings.ReplaceAll(p, "*/", "* /")
+		for line := range strings.SplitSeq(p, "\n") {
+			lines = append(lines, strings.TrimRight(" * "+line, " \t"))
+		}
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return "/**\n" + strings.Join(lines, "\n") + "\n */\n"
+}
+
+// paramDocs returns the Doxygen @param lines of the commented signals of a
+// message, as a docComment paragraph.
+func paramDocs(message vera.Message) string {
+	var params []string
+	for _, s := range message.Signals {
+		if s.Comment != "" {
+			params = append(params, fmt.Sprintf("@param %s %s", s.Name, s.Comment))
+		}
+	}
+
+	return strings.Join(params, "\n")
+}
+
+// cIdentifier replaces every character that is not allowed in a C
+// identifier with an underscore.
+func cIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// cString escapes s to be the content of a C string literal. Control
+// characters are written as octal escapes, which unlike hexadecimal ones
+// cannot swallow the characters following them.
+func cString(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, `\%03o`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// RangeCheck is what decoding does with signal values out of their
+// [min|max] range.
+type RangeCheck string
+
+const (
+	// RangeClamp clamps values to the range and flags them as
+	// vera_signal_out_of_range.
+	RangeClamp RangeCheck = "clamp"
+	// RangeReport keeps values as decoded and flags them as
+	// vera_signal_out_of_range.
+	RangeReport RangeCheck = "report"
+	// RangeError fails decoding with vera_err_out_of_bounds.
+	RangeError RangeCheck = "error"
+)
+
+// Options tunes the generated code.
+type Options struct {
+	// Node restricts the generated code to the messages it receives, for
+	// decoding, and transmits, for encoding. All messages are decoded and
+	// encoded when empty.
+	Node vera.Node
+	// RangeCheck defaults to RangeClamp when empty.
+	RangeCheck RangeCheck
+
+	// singleHeader is set by GenerateSingleHeader for all the templates it
+	// executes.
+	singleHeader bool
+}
+
+// Data is what the vera templates, and the SDK adapter ones, are executed
+// with.
+type Data struct {
+	// Messages are the messages received or transmitted by the node.
+	Messages   []vera.Message
+	RangeCheck RangeCheck
+	// SingleHeader is set when the header and the source, with the ones of
+	// the SDK adapters, are generated as a single file.
+	SingleHeader bool
+
+	node vera.Node
+}
+
+func NewData(config *vera.Config, opts Options) (*Data, error) {
+	if opts.Node != "" && len(config.Nodes) > 0 && !slices.Contains(config.Nodes, opts.Node) {
+		return nil, fmt.Errorf("node '%s' is not declared in BU_", opts.Node)
+	}
+
+	rangeCheck := opts.RangeCheck
+	switch rangeCheck {
+	case "":
+		rangeCheck = RangeClamp
+	case RangeClamp, RangeReport, RangeError:
+	default:
+		return nil, fmt.Errorf("range check must be one of %s, %s and %s: %s", RangeClamp, RangeReport, RangeError, rangeCheck)
+	}
+
+	data := &Data{
+		RangeCheck:   rangeCheck,
+		SingleHeader: opts.singleHeader,
+		node:         opts.Node,
+	}
+	for _, m := range config.Messages {
+		if data.Receives(m) || data.Transmits(m) {
+			data.Messages = append(data.Messages, m)
+		}
+	}
+
+	return data, nil
+}
+
+// Receives reports whether the generated code decodes the message.
+func (d *Data) Receives(message vera.Message) bool {
+	return d.node == "" || message.IsReceivedBy(d.node)
+}
+
+// Transmits reports whether the generated code encodes the message.
+func (d *Data) Transmits(message vera.Message) bool {
+	return d.node == "" || message.Transmitter == d.node
+}
+
+// API returns the specifier of the public functions, VERA_API in single
+// header mode so that VERA_STATIC can make them static inline.
+func (d *Data) API() string {
+	if d.SingleHeader {
+		return "VERA_API "
+	}
+
+	return ""
+}
+
+// Internal returns the specifier of the helper functions, static inline in
+// single header mode, where the SDK adapters are compiled with them.
+func (d *Data) Internal() string {
+	if d.SingleHeader {
+		return "static inline "
+	}
+
+	return ""
+}
+
+// CyclicMessages returns the transmitted messages with a cycle time, which
+// the generated scheduler sends periodically.
+func (d *Data) CyclicMessages() []vera.Message {
+	var messages []vera.Message
+	for _, m := range d.Messages {
+		if d.Transmits(m) && m.CycleTime() > 0 {
+			messages = append(messages, m)
+		}
+	}
+
+	return messages
+}
+
+// SupervisedMessages returns the received messages with a cycle time, whose
+// reception the generated code supervises.
+func (d *Data) SupervisedMessages() []vera.Message {
+	var messages []vera.Message
+	for _, m := range d.Messages {
+		if d.Receives(m) && m.CycleTime() > 0 {
+			messages = append(messages, m)
+		}
+	}
+
+	return messages
+}
+
+func GenerateHeader(w io.Writer, config *vera.Config, opts Options) error {
+	data, err := NewData(config, opts)
+	if err != nil {
+		return err
+	}
+
 	headerTemplateContent, err := templateFiles.ReadFile("vera.h.tmpl")
 	if err != nil {
 		return err
 	}
 
-	headerTmpl, err := template.New("vera.h").Parse(string(headerTemplateContent))
+	headerTmpl, err := template.New("vera.h").Funcs(TemplateFuncs).Parse(string(headerTemplateContent))
 	if err != nil {
 		return err
 	}
 
-	if err := headerTmpl.Execute(w, config); err != nil {
+	if err := headerTmpl.Execute(w, data); err != nil {
 		return nil
 	}
 
 	return nil
 }
 
-func GenerateSource(w io.Writer, config *vera.Config) error {
+func GenerateSource(w io.Writer, config *vera.Config, opts Options) error {
+	data, err := NewData(config, opts)
+	if err != nil {
+		return err
+	}
+
 	sourceTemplateContent, err := templateFiles.ReadFile("vera.c.tmpl")
 	if err != nil {
 		return err
 	}
 
-	sourceTmpl, err := template.New("vera.c").Parse(string(sourceTemplateContent))
+	sourceTmpl, err := template.New("vera.c").Funcs(TemplateFuncs).Parse(string(sourceTemplateContent))
 	if err != nil {
 		return err
 	}
 
-	if err := sourceTmpl.Execute(w, config); err != nil {
+	if err := sourceTmpl.Execute(w, data); err != nil {
 		return nil
 	}
 
 	return nil
 }
+
+// GenerateFunc generates a header or a source file, as GenerateHeader and
+// GenerateSource do.
+type GenerateFunc func(w io.Writer, config *vera.Config, opts Options) error
+
+// Adapter is the code generation of an SDK adapter.
+type Adapter struct {
+	Header GenerateFunc
+	Source GenerateFunc
+}
+
+// GenerateSingleHeader writes the header and the source, then the ones of
+// the adapters, as a single header. Sources are only compiled where
+// VERA_IMPLEMENTATION is defined, their helpers being static inline.
+func GenerateSingleHeader(w io.Writer, config *vera.Config, opts Options, adapters ...Adapter) error {
+	opts.singleHeader = true
+
+	headers := []GenerateFunc{GenerateHeader}
+	sources := []GenerateFunc{GenerateSource}
+	for _, a := range adapters {
+		headers = append(headers, a.Header)
+		sources = append(sources, a.Source)
+	}
+
+	for _, generate := range headers {
+		if err := generate(w, config, opts); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(w, "#if defined(VERA_IMPLEMENTATION) && !defined(VERA_IMPLEMENTED)\n#define VERA_IMPLEMENTED\n\n"); err != nil {
+		return err
+	}
+	for _, generate := range sources {
+		if err := generate(w, config, opts); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, "\n"); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "#endif // VERA_IMPLEMENTATION\n")
+
+	return err
+}
@@ -0,0 +1,835 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ApexCorse/vera"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConfig = `BO_ 123 Inverter: 8 Inverter
+	SG_ Torque : 0|16@1- (0.1,0) [-3276.8|3276.7] "Nm" VCU
+	SG_ Speed : 16|8@1+ (1,0) [0|255] "rpm" VCU
+	SG_ Flag : 24|1@1- (1,0) [-1|0] "" VCU
+	SG_ Wide : 25|33@1- (1,0) [-4294967296|4294967295] "" VCU`
+
+func parseTestConfig(t *testing.T, configStr string) *vera.Config {
+	t.Helper()
+
+	config, err := vera.Parse(strings.NewReader(configStr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	return config
+}
+
+func TestGenerateHeader(t *testing.T) {
+	t.Run("should take signed signals as int64_t in encode functions", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, "int64_t Torque")
+		a.Contains(header, "uint64_t Speed")
+		a.Contains(header, "int64_t Flag")
+		a.Contains(header, "int64_t Wide")
+		a.NotContains(header, "uint64_t Torque")
+	})
+}
+
+func TestGenerateSource(t *testing.T) {
+	t.Run("should sign extend signed signals and pack them as two's complement", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "_sign_extend(raw, signal->dlc)")
+		a.Contains(source, "int64_t Torque")
+		a.Contains(source, "_insert_data_in_payload(frame->data, (uint64_t)Torque, 0, 16, 0);")
+		a.Contains(source, "_insert_data_in_payload(frame->data, (uint64_t)Wide, 25, 33, 0);")
+	})
+}
+
+func TestGenerateValueDescriptions(t *testing.T) {
+	configStr := `BO_ 123 Transmission: 8 Gearbox
+	SG_ Gear : 0|4@1+ (1,0) [0|15] "" VCU
+VAL_ 123 Gear 15 "Not available" 0 "Neutral" 1 "1st" 2 "1st" ;`
+
+	t.Run("should declare an enum and a lookup function per described signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, `typedef enum {
+	vera_Transmission_Gear_Neutral = 0,
+	vera_Transmission_Gear_1st = 1,
+	vera_Transmission_Gear_1st_2 = 2,
+	vera_Transmission_Gear_Not_available = 15,
+} vera_Transmission_Gear_value_t;`)
+		a.Contains(header, "const char* vera_Transmission_Gear_to_string(int64_t value);")
+	})
+
+	t.Run("should define the lookup function", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "const char* vera_Transmission_Gear_to_string(int64_t value) {")
+		a.Contains(source, `case 15: return "Not available";`)
+		a.Contains(source, "default: return NULL;")
+	})
+
+	t.Run("should name the enums after the message too", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, `BO_ 1 Engine: 1 ECU
+	SG_ State : 0|4@1+ (1,0) [0|15] "" VCU
+
+BO_ 2 Pump: 1 ECU
+	SG_ State : 0|4@1+ (1,0) [0|15] "" VCU
+VAL_ 1 State 1 "Running" ;
+VAL_ 2 State 1 "Running" ;`)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, "vera_Engine_State_Running = 1,\n} vera_Engine_State_value_t;")
+		a.Contains(header, "vera_Pump_State_Running = 1,\n} vera_Pump_State_value_t;")
+		a.Contains(header, "const char* vera_Engine_State_to_string(int64_t value);")
+		a.Contains(header, "const char* vera_Pump_State_to_string(int64_t value);")
+	})
+
+	t.Run("should escape the descriptions in C strings", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, `BO_ 2 Pump: 1 ECU
+	SG_ State : 0|4@1+ (1,0) [0|15] "" VCU
+VAL_ 2 State 1 "Run \"fast\"" ;`)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+		a.Contains(buf.String(), `case 1: return "Run \"fast\"";`)
+	})
+}
+
+func TestCString(t *testing.T) {
+	t.Run("should escape quotes, backslashes and control characters", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal(`Run \"fast\"`, cString(`Run "fast"`))
+		a.Equal(`C:\\vera`, cString(`C:\vera`))
+		a.Equal(`a\nb\tc\0011`, cString("a\nb\tc\x011"))
+		a.Equal("Température", cString("Température"))
+	})
+}
+
+func TestCIdentifier(t *testing.T) {
+	t.Run("should replace characters not allowed in C identifiers", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal("Not_available", cIdentifier("Not available"))
+		a.Equal("Fault__over_temp_", cIdentifier("Fault (over-temp)"))
+		a.Equal("snake_case_42", cIdentifier("snake_case_42"))
+	})
+}
+
+func TestGenerateMultiplexing(t *testing.T) {
+	configStr := `BO_ 200 Diagnostics: 8 BMS
+	SG_ Service M : 0|8@1+ (1,0) [0|255] "" VCU
+	SG_ Subservice m1M : 8|8@1+ (1,0) [0|255] "" VCU
+	SG_ Counter m1 : 16|16@1+ (1,0) [0|65535] "" VCU
+	SG_ RawData m2 : 8|32@1+ (1,0) [0|4294967295] "" VCU
+SG_MUL_VAL_ 200 Subservice Service 1-1;
+SG_MUL_VAL_ 200 Counter Subservice 1-1, 4-6;`
+
+	t.Run("should describe the multiplexor of each signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, `.multiplexor = -1
+			};`)
+		a.Contains(source, `.multiplexor = 1,
+				.n_multiplex_ranges = 2,
+				.multiplex_ranges = (vera_multiplex_range_t[]){{1, 1}, {4, 6}}`)
+	})
+
+	t.Run("should only pack signals selected by their multiplexors", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "\n\t_insert_data_in_payload(frame->data, (uint64_t)Service, 0, 8, 0);")
+		a.Contains(source, "if (Service == 1) _insert_data_in_payload(frame->data, (uint64_t)Subservice, 8, 8, 0);")
+		a.Contains(source, "if ((Subservice == 1 || (Subservice >= 4 && Subservice <= 6)) && Service == 1) _insert_data_in_payload(frame->data, (uint64_t)Counter, 16, 16, 0);")
+		a.Contains(source, "if (Service == 2) _insert_data_in_payload(frame->data, (uint64_t)RawData, 8, 32, 0);")
+	})
+}
+
+func TestGenerateCANFD(t *testing.T) {
+	t.Run("should mark encoded FD frames", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, `BO_ 128 Telemetry: 64 Logger
+	SG_ Last : 496|16@1+ (1,0) [0|65535] "" VCU
+BO_ 129 Status: 8 Logger
+	SG_ State : 0|8@1+ (1,0) [0|255] "" VCU`)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, `	frame->dlc = 64;
+	frame->is_fd = true;`)
+		a.Contains(source, `	frame->dlc = 8;
+	frame->is_fd = false;`)
+		a.Contains(source, "_insert_data_in_payload(frame->data, (uint64_t)Last, 496, 16, 0);")
+	})
+}
+
+func TestGenerateExtendedIDs(t *testing.T) {
+	t.Run("should dispatch on both ID and frame format", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, `BO_ 256 Standard: 1 Engine
+	SG_ StandardValue : 0|8@1+ (1,0) [0|255] "" VCU
+BO_ 2147483904 Extended: 1 Engine
+	SG_ ExtendedValue : 0|8@1+ (1,0) [0|255] "" VCU`)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "uint32_t id = frame->id | (frame->is_extended_id ? VERA_EXTENDED_ID_FLAG : 0);")
+		a.Contains(source, "		case 0x100: {")
+		a.Contains(source, "		case 0x100 | VERA_EXTENDED_ID_FLAG: {")
+		a.Contains(source, `	frame->id = 0x100;
+	frame->is_extended_id = true;`)
+	})
+}
+
+func TestGenerateTypedMessages(t *testing.T) {
+	t.Run("should declare a struct with the smallest integer type per signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, `typedef struct {
+	int16_t  Torque;
+	uint8_t  Speed;
+	int8_t   Flag;
+	int64_t  Wide;
+} vera_Inverter_t;`)
+		a.Contains(header, "vera_err_t vera_unpack_Inverter(const uint8_t* data, vera_Inverter_t* message);")
+		a.Contains(header, "vera_err_t vera_pack_Inverter(const vera_Inverter_t* message, uint8_t* data);")
+	})
+
+	t.Run("should unpack multiplexors before the signals they select", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, `BO_ 200 CellVoltages: 8 BMS
+	SG_ Cell0 m0 : 8|16@1+ (0.001,0) [0|5] "V" VCU
+	SG_ CellIndex M : 0|8@1+ (1,0) [0|255] "" VCU`)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, `	message->CellIndex = (uint8_t)_get_payload_by_start_and_length(data, 0, 8, 0);
+	if (message->CellIndex == 0) message->Cell0 = (uint16_t)_get_payload_by_start_and_length(data, 8, 16, 0);`)
+	})
+
+	t.Run("should sign extend signed signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		a.Contains(buf.String(), "message->Torque = (int16_t)_sign_extend(_get_payload_by_start_and_length(data, 0, 16, 0), 16);")
+	})
+}
+
+func TestCType(t *testing.T) {
+	t.Run("should pick the smallest integer type fitting the signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal("uint8_t", cType(vera.Signal{Length: 1}))
+		a.Equal("uint8_t", cType(vera.Signal{Length: 8}))
+		a.Equal("uint16_t", cType(vera.Signal{Length: 9}))
+		a.Equal("int32_t", cType(vera.Signal{Length: 17, Signed: true}))
+		a.Equal("int64_t", cType(vera.Signal{Length: 33, Signed: true}))
+		a.Equal("uint64_t", cType(vera.Signal{Length: 64}))
+	})
+
+	t.Run("should use float and double for IEEE signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal("float", cType(vera.Signal{Length: 32, Signed: true, ValueType: vera.FloatValue}))
+		a.Equal("double", cType(vera.Signal{Length: 64, ValueType: vera.DoubleValue}))
+	})
+}
+
+func TestGeneratePhysicalEncoding(t *testing.T) {
+	t.Run("should declare encode functions taking physical values", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		a.Contains(buf.String(), `vera_err_t vera_encode_Inverter_phys(
+	vera_can_tx_frame_t* frame,
+	double Torque,
+	double Speed,
+	double Flag,
+	double Wide
+);`)
+	})
+
+	t.Run("should convert physical values with the signal scaling and range", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, `	err = _physical_to_raw(Torque, 0.1, 0, -3276.8, 3276.7, 16, true, 0, &value);
+	if (err != vera_err_ok) return err;
+	raw.Torque = (int16_t)value;`)
+		a.Contains(source, "err = _physical_to_raw(Speed, 1, 0, 0, 255, 8, false, 0, &value);")
+	})
+
+	t.Run("should only convert signals selected by their multiplexors", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, `BO_ 200 CellVoltages: 8 BMS
+	SG_ Cell0 m0 : 8|16@1+ (0.001,0) [0|5] "V" VCU
+	SG_ CellIndex M : 0|8@1+ (1,0) [0|255] "" VCU`)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		a.Contains(buf.String(), `	if (raw.CellIndex == 0) {
+		err = _physical_to_raw(Cell0, 0.001, 0, 0, 5, 16, false, 0, &value);`)
+	})
+}
+
+func TestGenerateForNode(t *testing.T) {
+	configStr := `BU_: Engine VCU Dashboard
+
+BO_ 100 EngineStatus: 1 Engine
+	SG_ Rpm : 0|8@1+ (1,0) [0|255] "" VCU
+BO_ 101 TorqueRequest: 1 VCU
+	SG_ Torque : 0|8@1+ (1,0) [0|255] "" Engine
+BO_ 102 DashboardStatus: 1 Dashboard
+	SG_ Brightness : 0|8@1+ (1,0) [0|255] "" Dashboard`
+
+	t.Run("should only decode received and encode transmitted messages", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{Node: "Engine"})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "case 0x65: {")
+		a.NotContains(source, "case 0x64: {")
+		a.Contains(source, "vera_err_t vera_unpack_TorqueRequest(")
+		a.NotContains(source, "vera_encode_TorqueRequest(")
+		a.NotContains(source, "vera_pack_TorqueRequest(")
+
+		a.Contains(source, "vera_err_t vera_encode_EngineStatus(")
+		a.Contains(source, "vera_err_t vera_encode_EngineStatus_phys(")
+		a.Contains(source, "vera_err_t vera_pack_EngineStatus(")
+		a.NotContains(source, "vera_unpack_EngineStatus(")
+
+		a.NotContains(source, "DashboardStatus")
+	})
+
+	t.Run("should only declare what the node uses", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{Node: "Engine"})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, "} vera_TorqueRequest_t;\n\nvera_err_t vera_unpack_TorqueRequest(")
+		a.Contains(header, ");\n\nvera_err_t vera_pack_EngineStatus(")
+		a.NotContains(header, "vera_encode_TorqueRequest")
+		a.NotContains(header, "DashboardStatus")
+	})
+
+	t.Run("should return error for undeclared node", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{Node: "Engnie"})
+		a.NotNil(err)
+		a.Equal("node 'Engnie' is not declared in BU_", err.Error())
+	})
+}
+
+func TestGenerateComments(t *testing.T) {
+	configStr := `BO_ 100 EngineStatus: 2 Engine
+	SG_ Rpm : 0|8@1+ (1,0) [0|255] "" VCU
+	SG_ Mode : 8|2@1+ (1,0) [0|3] "" VCU
+	SG_ Spare : 10|2@1+ (1,0) [0|3] "" VCU
+VAL_ 100 Mode 0 "Off" 1 "On" ;
+CM_ BO_ 100 "Engine state.
+Sent every 10 ms.";
+CM_ SG_ 100 Rpm "Crankshaft speed";
+CM_ SG_ 100 Mode "Operating mode";`
+
+	t.Run("should document encode functions, structs and enums", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, `/**
+ * Operating mode
+ */
+typedef enum {`)
+		a.Contains(header, `/**
+ * Engine state.
+ * Sent every 10 ms.
+ *
+ * @param Rpm Crankshaft speed
+ * @param Mode Operating mode
+ */
+vera_err_t vera_encode_EngineStatus(`)
+		a.Contains(header, `/**
+ * Raw signal values of EngineStatus, packed and unpacked without copies or
+ * allocations.
+ *
+ * Engine state.
+ * Sent every 10 ms.
+ */
+typedef struct {`)
+	})
+}
+
+func TestDocComment(t *testing.T) {
+	t.Run("should skip empty paragraphs", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal("", docComment("", ""))
+		a.Equal("/**\n * Only\n */\n", docComment("", "Only"))
+	})
+
+	t.Run("should not let comments end the block early", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal("/**\n * Ends here * / or not\n *\n * Next\n */\n", docComment("Ends here */ or not", "Next"))
+	})
+}
+
+func TestGenerateTxScheduler(t *testing.T) {
+	configStr := `BU_: Engine VCU
+
+BO_ 100 EngineStatus: 1 Engine
+	SG_ Rpm : 0|8@1+ (1,0) [0|255] "" VCU
+BO_ 101 TorqueRequest: 1 VCU
+	SG_ Torque : 0|8@1+ (1,0) [0|255] "" Engine
+BO_ 102 EngineFault: 1 Engine
+	SG_ Code : 0|8@1+ (1,0) [0|255] "" VCU
+
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_DEF_DEF_ "GenMsgCycleTime" 0;
+BA_ "GenMsgCycleTime" BO_ 100 10;
+BA_ "GenMsgCycleTime" BO_ 101 20;`
+
+	t.Run("should flag the cyclic messages transmitted by the node", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{Node: "Engine"})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, "typedef struct {\n\tbool EngineStatus;\n} vera_tx_due_t;")
+		a.Contains(header, "vera_tx_due_t vera_tx_scheduler_tick(uint32_t now_ms);")
+	})
+
+	t.Run("should schedule every message at its cycle time", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "static uint32_t next_due_ms[2];")
+		a.Contains(source, "due.EngineStatus = _tx_schedule(now_ms, 10, !started, &next_due_ms[0]);")
+		a.Contains(source, "due.TorqueRequest = _tx_schedule(now_ms, 20, !started, &next_due_ms[1]);")
+		a.NotContains(source, "due.EngineFault")
+	})
+
+	t.Run("should not generate a scheduler without cyclic messages", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+		a.NotContains(buf.String(), "vera_tx_scheduler_tick")
+	})
+}
+
+func TestGenerateRxTimeouts(t *testing.T) {
+	configStr := `BU_: Engine VCU
+
+BO_ 100 EngineStatus: 1 Engine
+	SG_ Rpm : 0|8@1+ (1,0) [0|255] "" VCU
+BO_ 101 TorqueRequest: 1 VCU
+	SG_ Torque : 0|8@1+ (1,0) [0|255] "" Engine
+BO_ 102 EngineFault: 1 Engine
+	SG_ Code : 0|8@1+ (1,0) [0|255] "" VCU
+
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_DEF_DEF_ "GenMsgCycleTime" 0;
+BA_ "GenMsgCycleTime" BO_ 100 10;
+BA_ "GenMsgCycleTime" BO_ 101 20;`
+
+	t.Run("should flag the cyclic messages received by the node", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{Node: "VCU"})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, "typedef struct {\n\tbool EngineStatus;\n} vera_rx_timeouts_t;")
+		a.Contains(header, "vera_rx_timeouts_t vera_check_timeouts(uint64_t now);")
+		a.Contains(header, "#define VERA_TIMEOUT_CYCLES 3")
+	})
+
+	t.Run("should track the reception of cyclic messages", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{Node: "VCU"})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "static _rx_state_t rx_state_EngineStatus;")
+		a.Contains(source, "rx_state_EngineStatus.timestamp = frame->timestamp;")
+		a.Contains(source, "timeouts.EngineStatus = _is_timed_out(now, rx_state_EngineStatus.received ? rx_state_EngineStatus.timestamp : start, 10);")
+		a.NotContains(source, "rx_state_EngineFault")
+		a.NotContains(source, "rx_state_TorqueRequest")
+	})
+
+	t.Run("should not supervise without cyclic messages", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+		a.NotContains(buf.String(), "rx_state")
+		a.NotContains(buf.String(), "vera_check_timeouts")
+	})
+}
+
+func TestGenerateStartValues(t *testing.T) {
+	t.Run("should encode the start value of every signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig+`
+BA_DEF_ SG_ "GenSigStartValue" INT 0 0;
+BA_ "GenSigStartValue" SG_ 123 Torque -100;
+BA_ "GenSigStartValue" SG_ 123 Speed 20;`)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+		a.Contains(buf.String(), `vera_err_t vera_init_Inverter(vera_can_tx_frame_t* frame) {
+	return vera_encode_Inverter(
+		frame,
+		-100,
+		20,
+		0,
+		0
+	);
+}`)
+
+		buf.Reset()
+		err = GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+		a.Contains(buf.String(), "vera_err_t vera_init_Inverter(vera_can_tx_frame_t* frame);")
+	})
+}
+
+func TestGenerateIEEESignals(t *testing.T) {
+	configStr := `BO_ 129 ImuData: 8 Imu
+	SG_ AccelX : 0|32@1- (1,0) [-100|100] "m/s2" VCU
+	SG_ Status : 32|8@1- (1,0) [-128|127] "" VCU
+BO_ 130 LoggerTime: 8 Logger
+	SG_ Uptime : 0|64@1- (1,0) [0|1000000] "s" VCU
+SIG_VALTYPE_ 129 AccelX : 1;
+SIG_VALTYPE_ 130 Uptime : 2;`
+
+	t.Run("should take IEEE signals as float and double", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, "vera_err_t vera_encode_ImuData(\n\tvera_can_tx_frame_t* frame,\n\tfloat AccelX,\n\tint64_t Status\n);")
+		a.Contains(header, "\tfloat    AccelX;")
+		a.Contains(header, "\tdouble   Uptime;")
+	})
+
+	t.Run("should pack and unpack the bits of IEEE signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, configStr)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "_insert_data_in_payload(frame->data, _float_to_bits(AccelX), 0, 32, 0);")
+		a.Contains(source, "_insert_data_in_payload(data, _double_to_bits(message->Uptime), 0, 64, 0);")
+		a.Contains(source, "message->AccelX = _bits_to_float(_get_payload_by_start_and_length(data, 0, 32, 0));")
+		a.Contains(source, "message->Status = (int8_t)_sign_extend(_get_payload_by_start_and_length(data, 32, 8, 0), 8);")
+		a.Contains(source, "message->Uptime = _bits_to_double(_get_payload_by_start_and_length(data, 0, 64, 0));")
+		a.Contains(source, "raw.AccelX = _bits_to_float(value);")
+		a.Contains(source, ".value_type = 1,")
+	})
+}
+
+func TestGenerateRangeChecks(t *testing.T) {
+	t.Run("should clamp and flag out of range values by default", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, `	res->status = vera_signal_out_of_range;
+	if (res->value < signal->min)
+		res->value = signal->min;`)
+		a.NotContains(source, "return vera_err_out_of_bounds;\n}\n\nbool _is_signal_present")
+	})
+
+	t.Run("should only flag out of range values when reporting", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{RangeCheck: RangeReport})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "\tres->status = vera_signal_out_of_range;\n\n\treturn vera_err_ok;\n}")
+		a.NotContains(source, "res->value = signal->min;")
+	})
+
+	t.Run("should fail decoding out of range values with errors", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{RangeCheck: RangeError})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, "\tif (_is_in_range(res->value, signal->min, signal->max)) return vera_err_ok;\n\n\treturn vera_err_out_of_bounds;\n}")
+		a.NotContains(source, "vera_signal_out_of_range;")
+	})
+
+	t.Run("should return error for unknown range checks", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{RangeCheck: "saturate"})
+		a.NotNil(err)
+		a.Equal("range check must be one of clamp, report and error: saturate", err.Error())
+	})
+
+	t.Run("should set invalid and not available values", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig+`
+BA_DEF_ SG_ "GenSigInvalidValue" INT 0 0;
+BA_DEF_ SG_ "GenSigSNA" INT 0 0;
+BA_ "GenSigInvalidValue" SG_ 123 Speed 254;
+BA_ "GenSigSNA" SG_ 123 Speed 255;
+BA_ "GenSigSNA" SG_ 123 Torque -1;`)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.Contains(source, `				.multiplexor = -1,
+				.has_not_available_value = true,
+				.not_available_value = 0xffff
+			};`)
+		a.Contains(source, `				.multiplexor = -1,
+				.has_invalid_value = true,
+				.invalid_value = 0xfe,
+				.has_not_available_value = true,
+				.not_available_value = 0xff
+			};`)
+	})
+}
+
+func TestGenerateSingleHeader(t *testing.T) {
+	adapter := Adapter{
+		Header: func(w io.Writer, config *vera.Config, opts Options) error {
+			data, err := NewData(config, opts)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "// adapter header, single: %t\n", data.SingleHeader)
+			return err
+		},
+		Source: func(w io.Writer, config *vera.Config, opts Options) error {
+			_, err := io.WriteString(w, "// adapter source\n")
+			return err
+		},
+	}
+
+	t.Run("should guard the sources with VERA_IMPLEMENTATION", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSingleHeader(&buf, config, Options{}, adapter)
+		a.Nil(err)
+
+		header := buf.String()
+		a.True(strings.HasPrefix(header, "#ifndef VERA_H\n"))
+		a.Contains(header, "#endif // VERA_H\n\n// adapter header, single: true\n\n#if defined(VERA_IMPLEMENTATION) && !defined(VERA_IMPLEMENTED)\n#define VERA_IMPLEMENTED\n\n#include <string.h>")
+		a.True(strings.HasSuffix(header, "}\n\n// adapter source\n\n#endif // VERA_IMPLEMENTATION\n"))
+		a.NotContains(header, `#include "vera.h"`)
+	})
+
+	t.Run("should make helpers static inline and public functions VERA_API", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSingleHeader(&buf, config, Options{})
+		a.Nil(err)
+
+		header := buf.String()
+		a.Contains(header, "#ifdef VERA_STATIC\n#define VERA_API static inline")
+		a.Contains(header, "static inline void _insert_data_in_payload(uint8_t* payload, uint64_t data, uint16_t start, uint8_t length, uint8_t endianness) {")
+		a.Contains(header, "VERA_API vera_err_t vera_decode_can_frame(\n\tvera_can_rx_frame_t*   frame,\n\tvera_decoding_result_t* result\n);")
+		a.Contains(header, "VERA_API vera_err_t vera_encode_Inverter(\n\tvera_can_tx_frame_t* frame,")
+		a.Contains(header, "static const size_t vera_n_signals_Inverter = 4;")
+		a.NotContains(header, "extern const size_t")
+		a.NotContains(header, "\nvoid _insert_data_in_payload(")
+	})
+
+	t.Run("should not change the split files", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := parseTestConfig(t, testConfig)
+
+		var buf bytes.Buffer
+		err := GenerateSource(&buf, config, Options{})
+		a.Nil(err)
+
+		source := buf.String()
+		a.True(strings.HasPrefix(source, "#include \"vera.h\"\n\n#include <string.h>"))
+		a.Contains(source, "\nvoid _insert_data_in_payload(")
+		a.NotContains(source, "VERA_API")
+	})
+}
@@ -0,0 +1,297 @@
+package vera
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind tells what happened to a message or signal between two
+// versions of a network.
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeRenamed ChangeKind = "renamed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Change is a difference between two versions of a network, found by Diff.
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Message is the name of the message, in the new network unless it was
+	// removed. Signal is the name of the signal, empty for changes of the
+	// message itself.
+	Message string `json:"message"`
+	Signal  string `json:"signal,omitempty"`
+	// Field is the property that changed, such as "id" or "factor", and Old
+	// and New its values. Renames hold the old name in Old.
+	Field string `json:"field,omitempty"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+	// Breaking is set for changes nodes built for the old network cannot
+	// cope with on the wire.
+	Breaking bool `json:"breaking"`
+}
+
+// String returns the change as, for example, "EngineSpeed.Speed: factor
+// changed from 0.1 to 0.2".
+func (c Change) String() string {
+	object := c.Message
+	if c.Signal != "" {
+		object += "." + c.Signal
+	}
+
+	switch c.Kind {
+	case ChangeRenamed:
+		return fmt.Sprintf("%s: renamed from %s", object, c.Old)
+	case ChangeChanged:
+		return fmt.Sprintf("%s: %s changed from %s to %s", object, c.Field, c.Old, c.New)
+	default:
+		return fmt.Sprintf("%s: %s", object, c.Kind)
+	}
+}
+
+// Diff returns the changes turning the old network into the new one.
+//
+// Messages are matched by name, then the remaining ones by ID, which makes
+// them renamed. Signals of matched messages are matched by name, then by
+// position and length. Changes of the wire format are breaking: changed
+// IDs, lengths, layouts, scaling and multiplexing, removed messages and
+// signals, and ranges wider than the old decoders accept. Added messages
+// and signals, renames, narrower ranges, units, topics, transmitters and
+// cycle times are not.
+func Diff(old, new *Config) []Change {
+	d := differ{
+		oldTopics: topicsBySignal(old),
+		newTopics: topicsBySignal(new),
+	}
+
+	matched := make([]*Message, len(old.Messages))
+	used := make([]bool, len(new.Messages))
+	for i := range old.Messages {
+		for j := range new.Messages {
+			if !used[j] && old.Messages[i].Name == new.Messages[j].Name {
+				matched[i], used[j] = &new.Messages[j], true
+				break
+			}
+		}
+	}
+	for i := range old.Messages {
+		for j := range new.Messages {
+			if matched[i] == nil && !used[j] && sameFrameID(&old.Messages[i], &new.Messages[j]) {
+				matched[i], used[j] = &new.Messages[j], true
+				break
+			}
+		}
+	}
+
+	for i := range old.Messages {
+		if matched[i] == nil {
+			d.add(Change{Kind: ChangeRemoved, Message: old.Messages[i].Name, Breaking: true})
+			continue
+		}
+		d.diffMessage(&old.Messages[i], matched[i])
+	}
+
+	for j := range new.Messages {
+		if !used[j] {
+			d.add(Change{Kind: ChangeAdded, Message: new.Messages[j].Name})
+		}
+	}
+
+	return d.changes
+}
+
+type differ struct {
+	oldTopics map[string]string
+	newTopics map[string]string
+	changes   []Change
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+// field adds a change of a message, or of one of its signals, if the old and
+// new values differ.
+func (d *differ) field(message, signal, field, old, new string, breaking bool) {
+	if old == new {
+		return
+	}
+
+	d.add(Change{
+		Kind:     ChangeChanged,
+		Message:  message,
+		Signal:   signal,
+		Field:    field,
+		Old:      old,
+		New:      new,
+		Breaking: breaking,
+	})
+}
+
+func (d *differ) diffMessage(old, new *Message) {
+	if old.Name != new.Name {
+		d.add(Change{Kind: ChangeRenamed, Message: new.Name, Old: old.Name})
+	}
+
+	d.field(new.Name, "", "id", formatFrameID(old), formatFrameID(new), true)
+	d.field(new.Name, "", "dlc", strconv.Itoa(int(old.DLC)), strconv.Itoa(int(new.DLC)), true)
+	d.field(new.Name, "", "transmitter", string(old.Transmitter), string(new.Transmitter), false)
+	d.field(new.Name, "", "cycle time", formatCycleTime(old.CycleTime()), formatCycleTime(new.CycleTime()), false)
+
+	matched := make([]*Signal, len(old.Signals))
+	used := make([]bool, len(new.Signals))
+	for i := range old.Signals {
+		for j := range new.Signals {
+			if !used[j] && old.Signals[i].Name == new.Signals[j].Name {
+				matched[i], used[j] = &new.Signals[j], true
+				break
+			}
+		}
+	}
+	for i := range old.Signals {
+		for j := range new.Signals {
+			if matched[i] == nil && !used[j] && sameSignalBits(&old.Signals[i], &new.Signals[j]) {
+				matched[i], used[j] = &new.Signals[j], true
+				break
+			}
+		}
+	}
+
+	for i := range old.Signals {
+		if matched[i] == nil {
+			d.add(Change{Kind: ChangeRemoved, Message: new.Name, Signal: old.Signals[i].Name, Breaking: true})
+			continue
+		}
+		d.diffSignal(new.Name, &old.Signals[i], matched[i])
+	}
+
+	for j := range new.Signals {
+		if !used[j] {
+			d.add(Change{Kind: ChangeAdded, Message: new.Name, Signal: new.Signals[j].Name})
+		}
+	}
+}
+
+func (d *differ) diffSignal(message string, old, new *Signal) {
+	if old.Name != new.Name {
+		d.add(Change{Kind: ChangeRenamed, Message: message, Signal: new.Name, Old: old.Name})
+	}
+
+	name := new.Name
+	d.field(message, name, "start bit", strconv.Itoa(int(old.StartBit)), strconv.Itoa(int(new.StartBit)), true)
+	d.field(message, name, "length", strconv.Itoa(int(old.Length)), strconv.Itoa(int(new.Length)), true)
+	d.field(message, name, "byte order", formatEndianness(old.Endianness), formatEndianness(new.Endianness), true)
+	d.field(message, name, "signedness", formatSigned(old.Signed), formatSigned(new.Signed), true)
+	d.field(message, name, "value type", formatValueType(old.ValueType), formatValueType(new.ValueType), true)
+	d.field(message, name, "factor", formatFloat(old.Factor), formatFloat(new.Factor), true)
+	d.field(message, name, "offset", formatFloat(old.Offset), formatFloat(new.Offset), true)
+	d.field(message, name, "multiplexing", formatMultiplexing(old), formatMultiplexing(new), true)
+
+	// Old decoders clamp or reject the values out of their range, so only
+	// narrowing it keeps them working. [0|0] is no range at all.
+	widened := !hasNoRange(old) && (hasNoRange(new) || new.Min < old.Min || new.Max > old.Max)
+	d.field(message, name, "range", formatRange(old), formatRange(new), widened)
+
+	d.field(message, name, "unit", strconv.Quote(old.Unit), strconv.Quote(new.Unit), false)
+	d.field(message, name, "topic", formatTopic(d.oldTopics[old.Name]), formatTopic(d.newTopics[new.Name]), false)
+}
+
+// topicsBySignal maps signal names to their TP_ topics.
+func topicsBySignal(c *Config) map[string]string {
+	topics := make(map[string]string, len(c.Topics))
+	for _, t := range c.Topics {
+		topics[t.Signal] = t.Topic
+	}
+
+	return topics
+}
+
+func hasNoRange(s *Signal) bool {
+	return s.Min == 0 && s.Max == 0
+}
+
+func sameFrameID(m1, m2 *Message) bool {
+	return m1.ID == m2.ID && m1.IsExtended == m2.IsExtended
+}
+
+func sameSignalBits(s1, s2 *Signal) bool {
+	return s1.StartBit == s2.StartBit && s1.Length == s2.Length && s1.Endianness == s2.Endianness
+}
+
+func formatFrameID(m *Message) string {
+	if m.IsExtended {
+		return fmt.Sprintf("%#x (extended)", m.ID)
+	}
+
+	return fmt.Sprintf("%#x", m.ID)
+}
+
+func formatCycleTime(cycleTime uint32) string {
+	if cycleTime == 0 {
+		return "none"
+	}
+
+	return fmt.Sprintf("%d ms", cycleTime)
+}
+
+func formatEndianness(e Endianness) string {
+	if e == BigEndian {
+		return "big endian"
+	}
+
+	return "little endian"
+}
+
+func formatSigned(signed bool) string {
+	if signed {
+		return "signed"
+	}
+
+	return "unsigned"
+}
+
+func formatValueType(t ValueType) string {
+	switch t {
+	case FloatValue:
+		return "float"
+	case DoubleValue:
+		return "double"
+	default:
+		return "integer"
+	}
+}
+
+func formatRange(s *Signal) string {
+	return fmt.Sprintf("[%s|%s]", formatFloat(s.Min), formatFloat(s.Max))
+}
+
+func formatTopic(topic string) string {
+	if topic == "" {
+		return "none"
+	}
+
+	return topic
+}
+
+func formatMultiplexing(s *Signal) string {
+	var parts []string
+	if s.IsMultiplexor {
+		parts = append(parts, "multiplexor")
+	}
+	if s.IsMultiplexed() {
+		ranges := make([]string, 0, len(s.MultiplexValues))
+		for _, r := range s.MultiplexValues {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", r.Min, r.Max))
+		}
+		parts = append(parts, fmt.Sprintf("multiplexed by %s on %s", s.Multiplexor, strings.Join(ranges, ",")))
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+
+	return strings.Join(parts, ", ")
+}
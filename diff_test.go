@@ -0,0 +1,141 @@
+package vera
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const diffOldConfig = `VERSION ""
+
+BU_: Engine Dashboard
+
+BO_ 123 EngineSpeed: 4 Engine
+	SG_ Speed : 0|16@1+ (0.1,0) [0|6000] "RPM" Dashboard
+	SG_ Temperature : 16|8@1- (1,0) [-40|120] "C" Dashboard
+
+BO_ 124 Gear: 1 Engine
+	SG_ Gear : 0|4@1+ (1,0) [0|8] "" Dashboard
+
+BO_ 125 Legacy: 1 Engine
+	SG_ Flag : 0|1@1+ (1,0) [0|1] "" Dashboard
+
+TP_ Speed vehicle/engine/speed
+`
+
+const diffNewConfig = `VERSION ""
+
+BU_: Engine Dashboard
+
+BO_ 123 EngineSpeed: 4 Engine
+	SG_ Speed : 0|16@1+ (0.1,0) [0|5000] "rpm" Dashboard
+	SG_ OilTemperature : 16|8@1- (1,0) [-40|150] "C" Dashboard
+	SG_ Pressure : 24|8@1+ (0.5,0) [0|127.5] "bar" Dashboard
+
+BO_ 126 Transmission: 1 Engine
+	SG_ Gear : 0|4@1+ (2,0) [0|8] "" Dashboard
+
+BO_ 127 Brakes: 1 Engine
+	SG_ Pedal : 0|8@1+ (1,0) [0|100] "%" Dashboard
+
+TP_ Speed vehicle/engine/rpm
+`
+
+func TestDiff(t *testing.T) {
+	t.Run("should report no changes between the same networks", func(t *testing.T) {
+		a := assert.New(t)
+
+		old, err := Parse(strings.NewReader(diffOldConfig))
+		a.Nil(err)
+		new, err := Parse(strings.NewReader(diffOldConfig))
+		a.Nil(err)
+
+		a.Empty(Diff(old, new))
+	})
+
+	t.Run("should report changes between networks", func(t *testing.T) {
+		a := assert.New(t)
+
+		old, err := Parse(strings.NewReader(diffOldConfig))
+		a.Nil(err)
+		new, err := Parse(strings.NewReader(diffNewConfig))
+		a.Nil(err)
+
+		a.Equal([]Change{
+			{Kind: ChangeChanged, Message: "EngineSpeed", Signal: "Speed", Field: "range", Old: "[0|6000]", New: "[0|5000]"},
+			{Kind: ChangeChanged, Message: "EngineSpeed", Signal: "Speed", Field: "unit", Old: `"RPM"`, New: `"rpm"`},
+			{Kind: ChangeChanged, Message: "EngineSpeed", Signal: "Speed", Field: "topic", Old: "vehicle/engine/speed", New: "vehicle/engine/rpm"},
+			{Kind: ChangeRenamed, Message: "EngineSpeed", Signal: "OilTemperature", Old: "Temperature"},
+			{Kind: ChangeChanged, Message: "EngineSpeed", Signal: "OilTemperature", Field: "range", Old: "[-40|120]", New: "[-40|150]", Breaking: true},
+			{Kind: ChangeAdded, Message: "EngineSpeed", Signal: "Pressure"},
+			{Kind: ChangeRemoved, Message: "Gear", Breaking: true},
+			{Kind: ChangeRemoved, Message: "Legacy", Breaking: true},
+			{Kind: ChangeAdded, Message: "Transmission"},
+			{Kind: ChangeAdded, Message: "Brakes"},
+		}, Diff(old, new))
+	})
+
+	t.Run("should match renamed messages by ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		old := &Config{Messages: []Message{{Name: "Gear", ID: 124, DLC: 1, Signals: []Signal{
+			{Name: "Gear", Length: 4, Factor: 1},
+		}}}}
+		new := &Config{Messages: []Message{{Name: "Transmission", ID: 124, DLC: 2, Signals: []Signal{
+			{Name: "Gear", Length: 4, Factor: 2, Signed: true},
+		}}}}
+
+		a.Equal([]Change{
+			{Kind: ChangeRenamed, Message: "Transmission", Old: "Gear"},
+			{Kind: ChangeChanged, Message: "Transmission", Field: "dlc", Old: "1", New: "2", Breaking: true},
+			{Kind: ChangeChanged, Message: "Transmission", Signal: "Gear", Field: "signedness", Old: "unsigned", New: "signed", Breaking: true},
+			{Kind: ChangeChanged, Message: "Transmission", Signal: "Gear", Field: "factor", Old: "1", New: "2", Breaking: true},
+		}, Diff(old, new))
+	})
+
+	t.Run("should report changed IDs and layouts as breaking", func(t *testing.T) {
+		a := assert.New(t)
+
+		old := &Config{Messages: []Message{{Name: "Status", ID: 0x100, DLC: 8, Transmitter: "Engine", Signals: []Signal{
+			{Name: "Mode", StartBit: 0, Length: 4, Factor: 1},
+		}}}}
+		new := &Config{Messages: []Message{{Name: "Status", ID: 0x100, IsExtended: true, DLC: 8, Transmitter: "BMS", Signals: []Signal{
+			{Name: "Mode", StartBit: 7, Length: 4, Endianness: BigEndian, Factor: 1, IsMultiplexor: true},
+		}}}}
+
+		a.Equal([]Change{
+			{Kind: ChangeChanged, Message: "Status", Field: "id", Old: "0x100", New: "0x100 (extended)", Breaking: true},
+			{Kind: ChangeChanged, Message: "Status", Field: "transmitter", Old: "Engine", New: "BMS"},
+			{Kind: ChangeChanged, Message: "Status", Signal: "Mode", Field: "start bit", Old: "0", New: "7", Breaking: true},
+			{Kind: ChangeChanged, Message: "Status", Signal: "Mode", Field: "byte order", Old: "little endian", New: "big endian", Breaking: true},
+			{Kind: ChangeChanged, Message: "Status", Signal: "Mode", Field: "multiplexing", Old: "none", New: "multiplexor", Breaking: true},
+		}, Diff(old, new))
+	})
+
+	t.Run("should treat [0|0] as no range", func(t *testing.T) {
+		a := assert.New(t)
+
+		old := &Config{Messages: []Message{{Name: "Status", Signals: []Signal{{Name: "Mode", Length: 4, Factor: 1}}}}}
+		new := &Config{Messages: []Message{{Name: "Status", Signals: []Signal{{Name: "Mode", Length: 4, Factor: 1, Max: 10}}}}}
+
+		changes := Diff(old, new)
+		a.Len(changes, 1)
+		a.False(changes[0].Breaking)
+
+		changes = Diff(new, old)
+		a.Len(changes, 1)
+		a.True(changes[0].Breaking)
+	})
+}
+
+func TestChangeString(t *testing.T) {
+	t.Run("should describe changes", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal("EngineSpeed.Speed: factor changed from 0.1 to 0.2", Change{Kind: ChangeChanged, Message: "EngineSpeed", Signal: "Speed", Field: "factor", Old: "0.1", New: "0.2"}.String())
+		a.Equal("Transmission: renamed from Gear", Change{Kind: ChangeRenamed, Message: "Transmission", Old: "Gear"}.String())
+		a.Equal("EngineSpeed.Pressure: added", Change{Kind: ChangeAdded, Message: "EngineSpeed", Signal: "Pressure"}.String())
+		a.Equal("Legacy: removed", Change{Kind: ChangeRemoved, Message: "Legacy"}.String())
+	})
+}
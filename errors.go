@@ -1,10 +1,182 @@
 package vera
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 )
 
+// Severity tells whether a diagnostic makes a configuration unusable.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// ParseSeverity returns the severity named by String.
+func ParseSeverity(name string) (Severity, error) {
+	switch name {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	default:
+		return 0, fmt.Errorf("severity must be error or warning: %s", name)
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = severity
+	return nil
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("severity(%d)", int(s))
+	}
+}
+
+// Codes of the diagnostics reported by Parse, Config.Validate and Merge.
+const (
+	// CodeSyntax is reported for statements that cannot be parsed.
+	CodeSyntax = "syntax"
+	// CodeInvalidReference is reported for statements referring to unknown
+	// or unsuitable messages and signals.
+	CodeInvalidReference = "invalid-reference"
+	CodeInvalidTopic     = "invalid-topic"
+	CodeInvalidAttribute = "invalid-attribute"
+	CodeInvalidMessage   = "invalid-message"
+	CodeInvalidSignal    = "invalid-signal"
+	CodeUndeclaredNode   = "undeclared-node"
+	// CodeMergeConflict is reported by Merge for definitions of different
+	// files that cannot be merged.
+	CodeMergeConflict = "merge-conflict"
+)
+
+// Diagnostic is a problem found in a configuration.
+type Diagnostic struct {
+	File string `json:"file,omitempty"`
+	// Line and Column are counted from 1, and are 0 when unknown.
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+}
+
+// Error returns the diagnostic as file:line:column: message. Without a
+// file, it returns it as line N: message, N being counted from 0 as vera
+// errors always were.
+func (d *Diagnostic) Error() string {
+	if d.File == "" {
+		if d.Line == 0 {
+			return d.Message
+		}
+		return fmt.Sprintf("line %d: %s", d.Line-1, d.Message)
+	}
+
+	location := d.File
+	if d.Line > 0 {
+		location += fmt.Sprintf(":%d", d.Line)
+		if d.Column > 0 {
+			location += fmt.Sprintf(":%d", d.Column)
+		}
+	}
+
+	return location + ": " + d.Message
+}
+
+// Diagnostics are all the problems found in a configuration, returned as
+// an error by Parse and Config.Validate.
+type Diagnostics []Diagnostic
+
+// Error returns the diagnostics one per line.
+func (d Diagnostics) Error() string {
+	lines := make([]string, 0, len(d))
+	for i := range d {
+		lines = append(lines, d[i].Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// HasErrors reports whether any diagnostic has SeverityError.
+func (d Diagnostics) HasErrors() bool {
+	for i := range d {
+		if d[i].Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Err returns the diagnostics as an error, or nil if there are none.
+func (d Diagnostics) Err() error {
+	if len(d) == 0 {
+		return nil
+	}
+
+	return d
+}
+
+// add appends err as a diagnostic with the given code, unless it already
+// has one, and file.
+func (d *Diagnostics) add(err error, code string, file string) {
+	diagnostic := asDiagnostic(err)
+	if diagnostic.Code == "" {
+		diagnostic.Code = code
+	}
+	if diagnostic.File == "" {
+		diagnostic.File = file
+	}
+
+	*d = append(*d, diagnostic)
+}
+
+// asDiagnostic returns err if it is a diagnostic, or an error diagnostic
+// without location holding its message.
+func asDiagnostic(err error) Diagnostic {
+	var diagnostic *Diagnostic
+	if errors.As(err, &diagnostic) && diagnostic.Error() == err.Error() {
+		return *diagnostic
+	}
+
+	return Diagnostic{
+		Severity: SeverityError,
+		Message:  err.Error(),
+	}
+}
+
+// withCode sets the code of a diagnostic returned by errorAtLine.
+func withCode(err error, code string) error {
+	var diagnostic *Diagnostic
+	if errors.As(err, &diagnostic) && diagnostic.Code == "" {
+		diagnostic.Code = code
+	}
+
+	return err
+}
+
 func errorAtLine(lineNumber int, format string, a ...any) error {
-	errStr := fmt.Sprintf(format, a...)
-	return fmt.Errorf("line %d: %s", lineNumber, errStr)
+	return &Diagnostic{
+		Line:     lineNumber + 1,
+		Severity: SeverityError,
+		Message:  fmt.Sprintf(format, a...),
+	}
 }
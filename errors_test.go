@@ -0,0 +1,83 @@
+package vera
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticError(t *testing.T) {
+	t.Run("should count lines from 0 without a file", func(t *testing.T) {
+		a := assert.New(t)
+
+		d := &Diagnostic{Line: 3, Column: 2, Message: "message DLC must be a base 10 integer"}
+		a.Equal("line 2: message DLC must be a base 10 integer", d.Error())
+	})
+
+	t.Run("should prefix file, line and column", func(t *testing.T) {
+		a := assert.New(t)
+
+		d := &Diagnostic{File: "car.dbc", Line: 3, Column: 2, Message: "message DLC must be a base 10 integer"}
+		a.Equal("car.dbc:3:2: message DLC must be a base 10 integer", d.Error())
+
+		d.Column = 0
+		a.Equal("car.dbc:3: message DLC must be a base 10 integer", d.Error())
+
+		d.Line = 0
+		a.Equal("car.dbc: message DLC must be a base 10 integer", d.Error())
+	})
+
+	t.Run("should return the message alone without location", func(t *testing.T) {
+		a := assert.New(t)
+
+		d := &Diagnostic{Message: "duplicate signal topic: vehicle/speed"}
+		a.Equal("duplicate signal topic: vehicle/speed", d.Error())
+	})
+}
+
+func TestDiagnostics(t *testing.T) {
+	t.Run("should return one diagnostic per line", func(t *testing.T) {
+		a := assert.New(t)
+
+		diagnostics := Diagnostics{
+			{Line: 1, Message: "first"},
+			{Line: 5, Message: "second"},
+		}
+		a.Equal("line 0: first\nline 4: second", diagnostics.Error())
+	})
+
+	t.Run("should return nil error without diagnostics", func(t *testing.T) {
+		a := assert.New(t)
+
+		var diagnostics Diagnostics
+		a.Nil(diagnostics.Err())
+		a.False(diagnostics.HasErrors())
+	})
+
+	t.Run("should report errors", func(t *testing.T) {
+		a := assert.New(t)
+
+		diagnostics := Diagnostics{{Severity: SeverityWarning}}
+		a.False(diagnostics.HasErrors())
+
+		diagnostics = append(diagnostics, Diagnostic{Severity: SeverityError})
+		a.True(diagnostics.HasErrors())
+	})
+
+	t.Run("should add errors with codes and files", func(t *testing.T) {
+		a := assert.New(t)
+
+		var diagnostics Diagnostics
+		diagnostics.add(errorAtLine(2, "message ID must be a base 10 or hexadecimal integer"), CodeSyntax, "car.dbc")
+		diagnostics.add(withCode(errorAtLine(4, "signal length must be 32"), CodeInvalidSignal), CodeInvalidMessage, "car.dbc")
+		diagnostics.add(fmt.Errorf("network %w", errors.New("attribute 'BusType' is not defined in BA_DEF_")), CodeInvalidAttribute, "")
+
+		a.Equal(Diagnostics{
+			{File: "car.dbc", Line: 3, Severity: SeverityError, Code: CodeSyntax, Message: "message ID must be a base 10 or hexadecimal integer"},
+			{File: "car.dbc", Line: 5, Severity: SeverityError, Code: CodeInvalidSignal, Message: "signal length must be 32"},
+			{Severity: SeverityError, Code: CodeInvalidAttribute, Message: "network attribute 'BusType' is not defined in BA_DEF_"},
+		}, diagnostics)
+	})
+}
@@ -0,0 +1,19 @@
+#ifndef CAN_LLD_H
+#define CAN_LLD_H
+
+// Stand-in for the AutoDevKit CAN types the generated adapter uses, to test
+// it on the host.
+
+#include <stdint.h>
+
+typedef struct {
+	uint32_t ID;
+	uint8_t  DLC;
+	uint8_t  TYPE;
+	uint8_t  OPERATION;
+	uint8_t  data8[64];
+} CANRxFrame;
+
+typedef CANRxFrame CANTxFrame;
+
+#endif // CAN_LLD_H
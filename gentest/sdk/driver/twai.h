@@ -0,0 +1,27 @@
+#ifndef DRIVER_TWAI_H
+#define DRIVER_TWAI_H
+
+// Stand-in for the ESP-IDF TWAI types the generated adapter uses, to test
+// it on the host.
+
+#include <stddef.h>
+#include <stdint.h>
+
+typedef struct {
+	uint32_t id;
+	uint16_t dlc;
+	uint32_t ide : 1;
+	uint32_t rtr : 1;
+	uint32_t fdf : 1;
+	uint32_t brs : 1;
+	uint32_t esi : 1;
+	uint64_t timestamp;
+} twai_frame_header_t;
+
+typedef struct {
+	twai_frame_header_t header;
+	uint8_t*            buffer;
+	size_t              buffer_len;
+} twai_frame_t;
+
+#endif // DRIVER_TWAI_H
@@ -1,6 +1,8 @@
 #include "vera.h"
 #include "unity/unity.h"
 
+#include <string.h>
+
 void setUp(void) {}
 void tearDown(void) {}
 
@@ -8,7 +10,7 @@ void test_successful_decoding(void) {
 	vera_can_rx_frame_t frame = {
 		.id = 0x7b,
 		.dlc = 8,
-		.data = {0x00, 0x00, 0x7d, 0xf4, 0x0c, 0xe5, 0x64, 0x10},
+		.data = {0xf4, 0x7d, 0x00, 0x00, 0xce, 0xe0, 0x64, 0x10},
 	};
 	vera_decoded_signal_t signals[vera_n_signals_Message1];
 	vera_decoding_result_t result = {
@@ -26,6 +28,7 @@ void test_successful_decoding(void) {
 	TEST_ASSERT_EQUAL_STRING("EngineSpeed", decoded_signals[0].name); 
 	TEST_ASSERT_EQUAL_STRING("Engine/Metrics/Speed", decoded_signals[0].topic);
 	TEST_ASSERT_FLOAT_WITHIN(0.01, 3224.4, decoded_signals[0].value);
+	TEST_ASSERT_EQUAL(vera_signal_ok, decoded_signals[0].status);
 	TEST_ASSERT_EQUAL_STRING("ºC", decoded_signals[1].unit);
 	TEST_ASSERT_EQUAL_STRING("BatteryTemperature", decoded_signals[1].name);
 	TEST_ASSERT_EQUAL_FLOAT(606, decoded_signals[1].value);
@@ -40,22 +43,738 @@ void test_successful_encoding(void) {
 	TEST_ASSERT_EQUAL(vera_err_ok, err);
 	TEST_ASSERT_EQUAL(123, frame.id);
 	TEST_ASSERT_EQUAL(6, frame.dlc);
-	TEST_ASSERT_EQUAL(0x3c, frame.data[0]);
-	TEST_ASSERT_EQUAL(0x05, frame.data[1]);
-	TEST_ASSERT_EQUAL(0x5f, frame.data[2]);
-	TEST_ASSERT_EQUAL(0xd1, frame.data[3]);
-	TEST_ASSERT_EQUAL(0x14, frame.data[4]);
-	TEST_ASSERT_EQUAL(0x50, frame.data[5]);
+	TEST_ASSERT_EQUAL(0xd1, frame.data[0]);
+	TEST_ASSERT_EQUAL(0x5f, frame.data[1]);
+	TEST_ASSERT_EQUAL(0x05, frame.data[2]);
+	TEST_ASSERT_EQUAL(0x3c, frame.data[3]);
+	TEST_ASSERT_EQUAL(0x45, frame.data[4]);
+	TEST_ASSERT_EQUAL(0x01, frame.data[5]);
+	TEST_ASSERT_EQUAL(0x00, frame.data[6]);
+	TEST_ASSERT_EQUAL(0x00, frame.data[7]);
+}
+
+void test_successful_big_endian_decoding(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x7c,
+		.dlc = 4,
+		.data = {0x12, 0x34, 0xab, 0xc5},
+	};
+	vera_decoded_signal_t signals[vera_n_signals_InverterStatus];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(3, result.n_signals);
+
+	TEST_ASSERT_EQUAL_STRING("Torque", signals[0].name);
+	TEST_ASSERT_EQUAL_FLOAT(0x1234, signals[0].value);
+	TEST_ASSERT_EQUAL_STRING("Current", signals[1].name);
+	TEST_ASSERT_EQUAL_FLOAT(0xabc, signals[1].value);
+	TEST_ASSERT_EQUAL_STRING("Status", signals[2].name);
+	TEST_ASSERT_EQUAL_FLOAT(0x5, signals[2].value);
+}
+
+void test_big_endian_signal_out_of_bounds(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x7c,
+		.dlc = 3,
+		.data = {0x12, 0x34, 0xab},
+	};
+	vera_decoded_signal_t signals[vera_n_signals_InverterStatus];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_out_of_bounds, err);
+}
+
+void test_successful_big_endian_encoding(void) {
+	vera_can_tx_frame_t frame = {
+		.data = {0}
+	};
+	vera_err_t err = vera_encode_InverterStatus(&frame, 0x1234, 0xabc, 0x5);
+
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(124, frame.id);
+	TEST_ASSERT_EQUAL(4, frame.dlc);
+	TEST_ASSERT_EQUAL(0x12, frame.data[0]);
+	TEST_ASSERT_EQUAL(0x34, frame.data[1]);
+	TEST_ASSERT_EQUAL(0xab, frame.data[2]);
+	TEST_ASSERT_EQUAL(0xc5, frame.data[3]);
+}
+
+void test_signed_decoding(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x7d,
+		.dlc = 8,
+		.data = {0x81, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00},
+	};
+	vera_decoded_signal_t signals[vera_n_signals_SignedValues];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(3, result.n_signals);
+	TEST_ASSERT_EQUAL_FLOAT(-1, signals[0].value);
+	TEST_ASSERT_EQUAL_FLOAT(-64, signals[1].value);
+	TEST_ASSERT_EQUAL_FLOAT(-4294967296.0f, signals[2].value);
+}
+
+void test_signed_decoding_positive_values(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x7d,
+		.dlc = 8,
+		.data = {0x7e, 0xff, 0xff, 0xff, 0x7f, 0x00, 0x00, 0x00},
+	};
+	vera_decoded_signal_t signals[vera_n_signals_SignedValues];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_FLOAT(0, signals[0].value);
+	TEST_ASSERT_EQUAL_FLOAT(63, signals[1].value);
+	TEST_ASSERT_EQUAL_FLOAT(2147483647.0f, signals[2].value);
+}
+
+void test_signed_full_width_decoding(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x7e,
+		.dlc = 8,
+		.data = {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	};
+	vera_decoded_signal_t signals[vera_n_signals_SignedFullWidth];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_FLOAT(-1, signals[0].value);
+}
+
+void test_signed_encoding(void) {
+	vera_can_tx_frame_t frame = {
+		.data = {0}
+	};
+	vera_err_t err = vera_encode_SignedValues(&frame, -1, -64, -4294967296LL);
+
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(0x81, frame.data[0]);
+	TEST_ASSERT_EQUAL(0x00, frame.data[1]);
+	TEST_ASSERT_EQUAL(0x00, frame.data[4]);
+	TEST_ASSERT_EQUAL(0x01, frame.data[5]);
+	TEST_ASSERT_EQUAL(0x00, frame.data[6]);
+}
+
+void test_signed_encoding_is_masked(void) {
+	vera_can_tx_frame_t frame = {
+		.data = {0}
+	};
+	vera_err_t err = vera_encode_SignedValues(&frame, 0, 63, -1);
+
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(0x7e, frame.data[0]);
+	TEST_ASSERT_EQUAL(0xff, frame.data[1]);
+	TEST_ASSERT_EQUAL(0xff, frame.data[4]);
+	TEST_ASSERT_EQUAL(0x01, frame.data[5]);
 	TEST_ASSERT_EQUAL(0x00, frame.data[6]);
 	TEST_ASSERT_EQUAL(0x00, frame.data[7]);
 }
 
+void test_signed_full_width_encoding(void) {
+	vera_can_tx_frame_t frame = {
+		.data = {0}
+	};
+	vera_err_t err = vera_encode_SignedFullWidth(&frame, -2);
+
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(0xfe, frame.data[0]);
+	for (uint8_t i = 1; i < 8; i++) {
+		TEST_ASSERT_EQUAL(0xff, frame.data[i]);
+	}
+}
+
+void test_value_descriptions(void) {
+	TEST_ASSERT_EQUAL(0, vera_InverterStatus_Status_Off);
+	TEST_ASSERT_EQUAL(2, vera_InverterStatus_Status_Running);
+	TEST_ASSERT_EQUAL(15, vera_InverterStatus_Status_Fault);
+
+	TEST_ASSERT_EQUAL_STRING("Ready", vera_InverterStatus_Status_to_string(vera_InverterStatus_Status_Ready));
+	TEST_ASSERT_EQUAL_STRING("Fault", vera_InverterStatus_Status_to_string(15));
+	TEST_ASSERT_NULL(vera_InverterStatus_Status_to_string(7));
+}
+
+void test_value_descriptions_of_signals_sharing_a_name(void) {
+	vera_PumpStatus_Status_value_t pump = vera_PumpStatus_Status_Priming;
+	vera_InverterStatus_Status_value_t inverter = vera_InverterStatus_Status_Ready;
+
+	TEST_ASSERT_EQUAL(1, pump);
+	TEST_ASSERT_EQUAL(1, inverter);
+	TEST_ASSERT_EQUAL_STRING("Priming", vera_PumpStatus_Status_to_string(pump));
+	TEST_ASSERT_EQUAL_STRING("Running \"fast\"", vera_PumpStatus_Status_to_string(2));
+	TEST_ASSERT_EQUAL_STRING("Ready", vera_InverterStatus_Status_to_string(inverter));
+}
+
+void test_multiplexed_decoding(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x7f,
+		.dlc = 8,
+		.data = {0x01, 0x10, 0x27, 0xe8, 0x03, 0x00, 0x00, 0x00},
+	};
+	vera_decoded_signal_t signals[vera_n_signals_CellVoltages];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(3, result.n_signals);
+	TEST_ASSERT_EQUAL_STRING("CellIndex", signals[0].name);
+	TEST_ASSERT_EQUAL_FLOAT(1, signals[0].value);
+	TEST_ASSERT_EQUAL_STRING("Cell1", signals[1].name);
+	TEST_ASSERT_FLOAT_WITHIN(0.001, 10, signals[1].value);
+	TEST_ASSERT_EQUAL_STRING("PackVoltage", signals[2].name);
+	TEST_ASSERT_FLOAT_WITHIN(0.01, 100, signals[2].value);
+}
+
+void test_multiplexed_decoding_with_ranges(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x7f,
+		.dlc = 8,
+		.data = {0x07, 0x10, 0x27, 0xe8, 0x03, 0x00, 0x00, 0x00},
+	};
+	vera_decoded_signal_t signals[vera_n_signals_CellVoltages];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(3, result.n_signals);
+	TEST_ASSERT_EQUAL_STRING("CellHigh", signals[1].name);
+
+	frame.data[0] = 0x06;
+	result.n_signals = 0;
+	err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(2, result.n_signals);
+	TEST_ASSERT_EQUAL_STRING("CellIndex", signals[0].name);
+	TEST_ASSERT_EQUAL_STRING("PackVoltage", signals[1].name);
+}
+
+void test_multiplexed_encoding(void) {
+	vera_can_tx_frame_t frame = {
+		.data = {0}
+	};
+	vera_err_t err = vera_encode_CellVoltages(&frame, 1, 0xffff, 10000, 0xffff, 1000);
+
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(0x01, frame.data[0]);
+	TEST_ASSERT_EQUAL(0x10, frame.data[1]);
+	TEST_ASSERT_EQUAL(0x27, frame.data[2]);
+	TEST_ASSERT_EQUAL(0xe8, frame.data[3]);
+	TEST_ASSERT_EQUAL(0x03, frame.data[4]);
+}
+
+void test_fd_decoding(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x80,
+		.dlc = 64,
+		.is_fd = true,
+		.data = {0x34, 0x12},
+	};
+	frame.data[60] = 0xab;
+	frame.data[61] = 0xcd;
+	frame.data[62] = 0x78;
+	frame.data[63] = 0x56;
+	vera_decoded_signal_t signals[vera_n_signals_FdTelemetry];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(3, result.n_signals);
+	TEST_ASSERT_EQUAL_FLOAT(0x1234, signals[0].value);
+	TEST_ASSERT_EQUAL_FLOAT(0xabcd, signals[1].value);
+	TEST_ASSERT_EQUAL_FLOAT(0x5678, signals[2].value);
+}
+
+void test_fd_encoding(void) {
+	vera_can_tx_frame_t frame = {
+		.data = {0}
+	};
+	vera_err_t err = vera_encode_FdTelemetry(&frame, 0x1234, 0xabcd, 0x5678);
+
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(64, frame.dlc);
+	TEST_ASSERT_TRUE(frame.is_fd);
+	TEST_ASSERT_EQUAL(0x34, frame.data[0]);
+	TEST_ASSERT_EQUAL(0x12, frame.data[1]);
+	TEST_ASSERT_EQUAL(0xab, frame.data[60]);
+	TEST_ASSERT_EQUAL(0xcd, frame.data[61]);
+	TEST_ASSERT_EQUAL(0x78, frame.data[62]);
+	TEST_ASSERT_EQUAL(0x56, frame.data[63]);
+}
+
+void test_dlc_conversion(void) {
+	TEST_ASSERT_EQUAL(8, vera_dlc_to_length(8));
+	TEST_ASSERT_EQUAL(12, vera_dlc_to_length(9));
+	TEST_ASSERT_EQUAL(64, vera_dlc_to_length(15));
+	TEST_ASSERT_EQUAL(6, vera_length_to_dlc(6));
+	TEST_ASSERT_EQUAL(13, vera_length_to_dlc(32));
+	TEST_ASSERT_EQUAL(15, vera_length_to_dlc(64));
+	TEST_ASSERT_EQUAL(12, vera_length_to_dlc(21));
+}
+
+void test_extended_id_dispatch(void) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x100,
+		.dlc = 1,
+		.data = {0x2a},
+	};
+	vera_decoded_signal_t signals[1];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	vera_err_t err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(1, result.n_signals);
+	TEST_ASSERT_EQUAL_STRING("StandardValue", signals[0].name);
+
+	frame.is_extended_id = true;
+	result.n_signals = 0;
+	err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(1, result.n_signals);
+	TEST_ASSERT_EQUAL_STRING("ExtendedValue", signals[0].name);
+
+	frame.id = 0x7b;
+	result.n_signals = 0;
+	err = vera_decode_can_frame(&frame, &result);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL(0, result.n_signals);
+}
+
+void test_extended_id_encoding(void) {
+	vera_can_tx_frame_t frame = {
+		.data = {0}
+	};
+
+	vera_err_t err = vera_encode_ExtendedFrame(&frame, 42);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_HEX32(0x100, frame.id);
+	TEST_ASSERT_TRUE(frame.is_extended_id);
+
+	err = vera_encode_StandardFrame(&frame, 42);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_HEX32(0x100, frame.id);
+	TEST_ASSERT_FALSE(frame.is_extended_id);
+}
+
+void test_unpack(void) {
+	uint8_t data[8] = {0xf4, 0x7d, 0x00, 0x00, 0xce, 0xe0, 0x64, 0x10};
+	vera_Message1_t message;
+
+	vera_err_t err = vera_unpack_Message1(data, &message);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_UINT32(32244, message.EngineSpeed);
+	TEST_ASSERT_EQUAL_UINT16(206, message.BatteryTemperature);
+}
+
+void test_unpack_signed_and_big_endian(void) {
+	uint8_t signed_data[8] = {0x81, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00};
+	vera_SignedValues_t signed_values;
+
+	vera_err_t err = vera_unpack_SignedValues(signed_data, &signed_values);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_INT8(-1, signed_values.Flag);
+	TEST_ASSERT_EQUAL_INT8(-64, signed_values.Small);
+	TEST_ASSERT_TRUE(signed_values.Wide == -4294967296LL);
+
+	uint8_t inverter_data[4] = {0x12, 0x34, 0xab, 0xc5};
+	vera_InverterStatus_t inverter_status;
+
+	err = vera_unpack_InverterStatus(inverter_data, &inverter_status);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_UINT16(0x1234, inverter_status.Torque);
+	TEST_ASSERT_EQUAL_UINT16(0xabc, inverter_status.Current);
+	TEST_ASSERT_EQUAL_UINT8(0x5, inverter_status.Status);
+}
+
+void test_unpack_multiplexed(void) {
+	uint8_t data[8] = {0x01, 0x10, 0x27, 0xe8, 0x03, 0x00, 0x00, 0x00};
+	vera_CellVoltages_t message = {0};
+
+	vera_err_t err = vera_unpack_CellVoltages(data, &message);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_UINT8(1, message.CellIndex);
+	TEST_ASSERT_EQUAL_UINT16(0, message.Cell0);
+	TEST_ASSERT_EQUAL_UINT16(10000, message.Cell1);
+	TEST_ASSERT_EQUAL_UINT16(0, message.CellHigh);
+	TEST_ASSERT_EQUAL_UINT16(1000, message.PackVoltage);
+}
+
+void test_pack(void) {
+	vera_SignedValues_t message = {
+		.Flag = 0,
+		.Small = 63,
+		.Wide = -1
+	};
+	uint8_t data[8] = {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff};
+
+	vera_err_t err = vera_pack_SignedValues(&message, data);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_HEX8(0x7e, data[0]);
+	TEST_ASSERT_EQUAL_HEX8(0xff, data[1]);
+	TEST_ASSERT_EQUAL_HEX8(0xff, data[4]);
+	TEST_ASSERT_EQUAL_HEX8(0x01, data[5]);
+	TEST_ASSERT_EQUAL_HEX8(0x00, data[6]);
+	TEST_ASSERT_EQUAL_HEX8(0x00, data[7]);
+}
+
+void test_pack_unpack_round_trip(void) {
+	vera_CellVoltages_t message = {
+		.CellIndex = 4,
+		.Cell0 = 1,
+		.Cell1 = 2,
+		.CellHigh = 3456,
+		.PackVoltage = 4000
+	};
+	uint8_t data[8];
+
+	vera_err_t err = vera_pack_CellVoltages(&message, data);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+
+	vera_CellVoltages_t unpacked = {0};
+	err = vera_unpack_CellVoltages(data, &unpacked);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+	TEST_ASSERT_EQUAL_UINT8(4, unpacked.CellIndex);
+	TEST_ASSERT_EQUAL_UINT16(0, unpacked.Cell0);
+	TEST_ASSERT_EQUAL_UINT16(0, unpacked.Cell1);
+	TEST_ASSERT_EQUAL_UINT16(3456, unpacked.CellHigh);
+	TEST_ASSERT_EQUAL_UINT16(4000, unpacked.PackVoltage);
+}
+
+void test_pack_unpack_null_args(void) {
+	uint8_t data[8];
+	vera_Message1_t message;
+
+	TEST_ASSERT_EQUAL(vera_err_null_arg, vera_unpack_Message1(NULL, &message));
+	TEST_ASSERT_EQUAL(vera_err_null_arg, vera_unpack_Message1(data, NULL));
+	TEST_ASSERT_EQUAL(vera_err_null_arg, vera_pack_Message1(NULL, data));
+	TEST_ASSERT_EQUAL(vera_err_null_arg, vera_pack_Message1(&message, NULL));
+}
+
+void test_physical_encoding(void) {
+	vera_can_tx_frame_t frame;
+
+	vera_err_t err = vera_encode_Message1_phys(&frame, 3224.46, 430);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+
+	vera_Message1_t message = {0};
+	vera_unpack_Message1(frame.data, &message);
+	TEST_ASSERT_EQUAL_UINT32(32245, message.EngineSpeed);
+	TEST_ASSERT_EQUAL_UINT16(30, message.BatteryTemperature);
+	TEST_ASSERT_EQUAL_UINT32(123, frame.id);
+	TEST_ASSERT_EQUAL_UINT8(6, frame.dlc);
+}
+
+void test_physical_encoding_signed(void) {
+	vera_can_tx_frame_t frame;
+
+	vera_err_t err = vera_encode_SignedValues_phys(&frame, -1, -63.6, -4294967296.0);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+
+	vera_SignedValues_t message = {0};
+	vera_unpack_SignedValues(frame.data, &message);
+	TEST_ASSERT_EQUAL_INT8(-1, message.Flag);
+	TEST_ASSERT_EQUAL_INT8(-64, message.Small);
+	TEST_ASSERT_EQUAL_INT64(-4294967296LL, message.Wide);
+}
+
+void test_physical_encoding_out_of_bounds(void) {
+	vera_can_tx_frame_t frame;
+
+	TEST_ASSERT_EQUAL(vera_err_out_of_bounds, vera_encode_Message1_phys(&frame, 8000.1, 430));
+	TEST_ASSERT_EQUAL(vera_err_out_of_bounds, vera_encode_Message1_phys(&frame, -0.1, 430));
+	// In range, but negative once the offset is removed
+	TEST_ASSERT_EQUAL(vera_err_out_of_bounds, vera_encode_Message1_phys(&frame, 0, 399));
+	// In range, but not fitting in 12 bits once scaled
+	TEST_ASSERT_EQUAL(vera_err_out_of_bounds, vera_encode_Message1_phys(&frame, 0, 4496));
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_encode_Message1_phys(&frame, 0, 4495));
+	TEST_ASSERT_EQUAL(vera_err_null_arg, vera_encode_Message1_phys(NULL, 0, 430));
+}
+
+void test_physical_encoding_multiplexed(void) {
+	vera_can_tx_frame_t frame;
+
+	// Signals not selected by the multiplexor are not checked
+	vera_err_t err = vera_encode_CellVoltages_phys(&frame, 3, 1000, 1000, 3.4564, 400);
+	TEST_ASSERT_EQUAL(vera_err_ok, err);
+
+	vera_CellVoltages_t message = {0};
+	vera_unpack_CellVoltages(frame.data, &message);
+	TEST_ASSERT_EQUAL_UINT8(3, message.CellIndex);
+	TEST_ASSERT_EQUAL_UINT16(3456, message.CellHigh);
+	TEST_ASSERT_EQUAL_UINT16(4000, message.PackVoltage);
+
+	err = vera_encode_CellVoltages_phys(&frame, 0, 1000, 0, 0, 400);
+	TEST_ASSERT_EQUAL(vera_err_out_of_bounds, err);
+}
+
+void test_tx_scheduler(void) {
+	// Every cyclic message is due on the first tick
+	vera_tx_due_t due = vera_tx_scheduler_tick(1000);
+	TEST_ASSERT_TRUE(due.Message1);
+	TEST_ASSERT_TRUE(due.InverterStatus);
+
+	due = vera_tx_scheduler_tick(1005);
+	TEST_ASSERT_FALSE(due.Message1);
+	TEST_ASSERT_FALSE(due.InverterStatus);
+
+	due = vera_tx_scheduler_tick(1010);
+	TEST_ASSERT_TRUE(due.Message1);
+	TEST_ASSERT_FALSE(due.InverterStatus);
+
+	// A late tick doesn't shift the schedule
+	due = vera_tx_scheduler_tick(1023);
+	TEST_ASSERT_TRUE(due.Message1);
+	due = vera_tx_scheduler_tick(1029);
+	TEST_ASSERT_FALSE(due.Message1);
+	due = vera_tx_scheduler_tick(1030);
+	TEST_ASSERT_TRUE(due.Message1);
+
+	// Missed cycles are not sent in a burst
+	due = vera_tx_scheduler_tick(1100);
+	TEST_ASSERT_TRUE(due.Message1);
+	TEST_ASSERT_TRUE(due.InverterStatus);
+	due = vera_tx_scheduler_tick(1105);
+	TEST_ASSERT_FALSE(due.Message1);
+	due = vera_tx_scheduler_tick(1110);
+	TEST_ASSERT_TRUE(due.Message1);
+}
+
+void test_start_values(void) {
+	vera_can_tx_frame_t frame;
+	vera_can_tx_frame_t expected;
+
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_init_Message1(&frame));
+	vera_encode_Message1(&expected, 8000, 30);
+	TEST_ASSERT_EQUAL(123, frame.id);
+	TEST_ASSERT_EQUAL_UINT8_ARRAY(expected.data, frame.data, 6);
+
+	// Signals without start value start at 0
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_init_SignedValues(&frame));
+	vera_SignedValues_t message;
+	vera_unpack_SignedValues(frame.data, &message);
+	TEST_ASSERT_EQUAL_INT8(0, message.Flag);
+	TEST_ASSERT_EQUAL_INT8(-5, message.Small);
+	TEST_ASSERT_EQUAL_INT64(0, message.Wide);
+
+	TEST_ASSERT_EQUAL(vera_err_null_arg, vera_init_Message1(NULL));
+}
+
+void test_float_signals(void) {
+	vera_can_tx_frame_t tx_frame;
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_encode_ImuData(&tx_frame, -9.81f, 40.0f));
+	// -9.81f is 0xc11cf5c3
+	TEST_ASSERT_EQUAL_HEX8(0xc3, tx_frame.data[0]);
+	TEST_ASSERT_EQUAL_HEX8(0xf5, tx_frame.data[1]);
+	TEST_ASSERT_EQUAL_HEX8(0x1c, tx_frame.data[2]);
+	TEST_ASSERT_EQUAL_HEX8(0xc1, tx_frame.data[3]);
+
+	vera_can_rx_frame_t rx_frame = {
+		.id = tx_frame.id,
+		.dlc = tx_frame.dlc,
+	};
+	memcpy(rx_frame.data, tx_frame.data, tx_frame.dlc);
+	vera_decoded_signal_t signals[vera_n_signals_ImuData];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_decode_can_frame(&rx_frame, &result));
+	TEST_ASSERT_EQUAL(2, result.n_signals);
+	TEST_ASSERT_EQUAL_FLOAT(-9.81f, signals[0].value);
+	TEST_ASSERT_EQUAL_FLOAT(20.0f, signals[1].value);
+}
+
+void test_float_signals_physical_encoding(void) {
+	vera_can_tx_frame_t frame;
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_encode_ImuData_phys(&frame, -9.81, 20.25));
+
+	vera_ImuData_t message;
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_unpack_ImuData(frame.data, &message));
+	TEST_ASSERT_EQUAL_FLOAT(-9.81f, message.AccelX);
+	TEST_ASSERT_EQUAL_FLOAT(40.5f, message.Gyro);
+
+	TEST_ASSERT_EQUAL(vera_err_out_of_bounds, vera_encode_ImuData_phys(&frame, 100.5, 0));
+}
+
+void test_double_signals(void) {
+	// Unity is built without double support, hence the exact comparisons
+	vera_LoggerTime_t message = {.Uptime = 123456.789012};
+	uint8_t data[8];
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_pack_LoggerTime(&message, data));
+
+	vera_LoggerTime_t unpacked;
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_unpack_LoggerTime(data, &unpacked));
+	TEST_ASSERT_TRUE(unpacked.Uptime == 123456.789012);
+
+	vera_can_tx_frame_t frame;
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_encode_LoggerTime_phys(&frame, 98765.4321));
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_unpack_LoggerTime(frame.data, &unpacked));
+	TEST_ASSERT_TRUE(unpacked.Uptime == 98765.4321);
+}
+
+static void decode_at(uint32_t id, uint64_t timestamp) {
+	vera_can_rx_frame_t frame = {
+		.id = id,
+		.dlc = 8,
+		.timestamp = timestamp,
+	};
+	vera_decoded_signal_t signals[8];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_decode_can_frame(&frame, &result));
+}
+
+void test_rx_timeouts(void) {
+	// Both messages were last decoded by the tests above, at timestamp 0
+	vera_rx_timeouts_t timeouts = vera_check_timeouts(5000);
+	TEST_ASSERT_TRUE(timeouts.Message1);
+	TEST_ASSERT_TRUE(timeouts.InverterStatus);
+
+	decode_at(0x7b, 5000);
+	decode_at(0x7c, 5000);
+
+	timeouts = vera_check_timeouts(5030);
+	TEST_ASSERT_FALSE(timeouts.Message1);
+	TEST_ASSERT_FALSE(timeouts.InverterStatus);
+
+	// Message1 is sent every 10 ms, InverterStatus every 100 ms
+	timeouts = vera_check_timeouts(5031);
+	TEST_ASSERT_TRUE(timeouts.Message1);
+	TEST_ASSERT_FALSE(timeouts.InverterStatus);
+
+	timeouts = vera_check_timeouts(5301);
+	TEST_ASSERT_TRUE(timeouts.InverterStatus);
+
+	decode_at(0x7b, 5300);
+	timeouts = vera_check_timeouts(5301);
+	TEST_ASSERT_FALSE(timeouts.Message1);
+}
+
+static void decode_coolant_sensors(uint8_t temperature, uint8_t flow, vera_decoded_signal_t* signals) {
+	vera_can_rx_frame_t frame = {
+		.id = 0x83,
+		.dlc = 2,
+		.data = {temperature, flow},
+	};
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_decode_can_frame(&frame, &result));
+	TEST_ASSERT_EQUAL(2, result.n_signals);
+}
+
+void test_out_of_range_decoding(void) {
+	vera_decoded_signal_t signals[vera_n_signals_CoolantSensors];
+
+	decode_coolant_sensors(100, 50, signals);
+	TEST_ASSERT_EQUAL_FLOAT(60, signals[0].value);
+	TEST_ASSERT_EQUAL(vera_signal_ok, signals[0].status);
+	TEST_ASSERT_EQUAL_FLOAT(50, signals[1].value);
+	TEST_ASSERT_EQUAL(vera_signal_ok, signals[1].status);
+
+	// 200 - 40 and -56 are clamped to the signal ranges
+	decode_coolant_sensors(200, 0xc8, signals);
+	TEST_ASSERT_EQUAL_FLOAT(125, signals[0].value);
+	TEST_ASSERT_EQUAL(vera_signal_out_of_range, signals[0].status);
+	TEST_ASSERT_EQUAL_FLOAT(0, signals[1].value);
+	TEST_ASSERT_EQUAL(vera_signal_out_of_range, signals[1].status);
+}
+
+void test_invalid_and_not_available_decoding(void) {
+	vera_decoded_signal_t signals[vera_n_signals_CoolantSensors];
+
+	decode_coolant_sensors(254, 0xff, signals);
+	TEST_ASSERT_EQUAL(vera_signal_invalid, signals[0].status);
+	TEST_ASSERT_EQUAL_FLOAT(214, signals[0].value);
+	TEST_ASSERT_EQUAL(vera_signal_not_available, signals[1].status);
+
+	decode_coolant_sensors(255, 0xfe, signals);
+	TEST_ASSERT_EQUAL(vera_signal_not_available, signals[0].status);
+	TEST_ASSERT_EQUAL(vera_signal_out_of_range, signals[1].status);
+}
+
 int main(void) {
 	setvbuf(stdout, NULL, _IONBF, 0); // Disable stdout buffering
 	UNITY_BEGIN();
 
 	RUN_TEST(test_successful_decoding);
 	RUN_TEST(test_successful_encoding);
+	RUN_TEST(test_successful_big_endian_decoding);
+	RUN_TEST(test_big_endian_signal_out_of_bounds);
+	RUN_TEST(test_successful_big_endian_encoding);
+	RUN_TEST(test_signed_decoding);
+	RUN_TEST(test_signed_decoding_positive_values);
+	RUN_TEST(test_signed_full_width_decoding);
+	RUN_TEST(test_signed_encoding);
+	RUN_TEST(test_signed_encoding_is_masked);
+	RUN_TEST(test_signed_full_width_encoding);
+	RUN_TEST(test_value_descriptions);
+	RUN_TEST(test_value_descriptions_of_signals_sharing_a_name);
+	RUN_TEST(test_multiplexed_decoding);
+	RUN_TEST(test_multiplexed_decoding_with_ranges);
+	RUN_TEST(test_multiplexed_encoding);
+	RUN_TEST(test_fd_decoding);
+	RUN_TEST(test_fd_encoding);
+	RUN_TEST(test_dlc_conversion);
+	RUN_TEST(test_extended_id_dispatch);
+	RUN_TEST(test_extended_id_encoding);
+	RUN_TEST(test_unpack);
+	RUN_TEST(test_unpack_signed_and_big_endian);
+	RUN_TEST(test_unpack_multiplexed);
+	RUN_TEST(test_pack);
+	RUN_TEST(test_pack_unpack_round_trip);
+	RUN_TEST(test_pack_unpack_null_args);
+	RUN_TEST(test_physical_encoding);
+	RUN_TEST(test_physical_encoding_signed);
+	RUN_TEST(test_physical_encoding_out_of_bounds);
+	RUN_TEST(test_physical_encoding_multiplexed);
+	RUN_TEST(test_start_values);
+	RUN_TEST(test_float_signals);
+	RUN_TEST(test_float_signals_physical_encoding);
+	RUN_TEST(test_double_signals);
+	RUN_TEST(test_tx_scheduler);
+	RUN_TEST(test_rx_timeouts);
+	RUN_TEST(test_out_of_range_decoding);
+	RUN_TEST(test_invalid_and_not_available_decoding);
 	return UNITY_END();
 }
 
@@ -0,0 +1,38 @@
+#include "vera_autodevkit.h"
+#include "unity/unity.h"
+
+void setUp(void) {}
+void tearDown(void) {}
+
+static void decode_at(uint32_t id, uint64_t timestamp) {
+	CANRxFrame frame = {
+		.ID = id,
+		.DLC = 8,
+	};
+	vera_decoded_signal_t signals[8];
+	vera_decoding_result_t result = {
+		.n_signals = 0,
+		.decoded_signals = signals
+	};
+
+	TEST_ASSERT_EQUAL(vera_err_ok, vera_decode_autodevkit_rx_frame(&frame, timestamp, &result));
+}
+
+void test_autodevkit_rx_timeouts(void) {
+	decode_at(0x7b, 5000);
+	decode_at(0x7c, 5000);
+
+	vera_rx_timeouts_t timeouts = vera_check_timeouts(5030);
+	TEST_ASSERT_FALSE(timeouts.Message1);
+	TEST_ASSERT_FALSE(timeouts.InverterStatus);
+
+	timeouts = vera_check_timeouts(5031);
+	TEST_ASSERT_TRUE(timeouts.Message1);
+	TEST_ASSERT_FALSE(timeouts.InverterStatus);
+}
+
+int main(void) {
+	UNITY_BEGIN();
+	RUN_TEST(test_autodevkit_rx_timeouts);
+	return UNITY_END();
+}
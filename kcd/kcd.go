@@ -0,0 +1,304 @@
+// Package kcd imports Kayak KCD network definitions.
+package kcd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ApexCorse/vera"
+)
+
+type networkDefinition struct {
+	Nodes []node `xml:"Node"`
+	Buses []bus  `xml:"Bus"`
+}
+
+type node struct {
+	ID   string `xml:"id,attr"`
+	Name string `xml:"name,attr"`
+}
+
+type bus struct {
+	Name     string    `xml:"name,attr"`
+	Messages []message `xml:"Message"`
+}
+
+type message struct {
+	ID          string      `xml:"id,attr"`
+	Name        string      `xml:"name,attr"`
+	Length      string      `xml:"length,attr"`
+	Interval    uint32      `xml:"interval,attr"`
+	Format      string      `xml:"format,attr"`
+	Notes       string      `xml:"Notes"`
+	Producers   []nodeRef   `xml:"Producer>NodeRef"`
+	Signals     []signal    `xml:"Signal"`
+	Multiplexes []multiplex `xml:"Multiplex"`
+}
+
+type nodeRef struct {
+	ID string `xml:"id,attr"`
+}
+
+type signal struct {
+	Name      string    `xml:"name,attr"`
+	Offset    uint16    `xml:"offset,attr"`
+	Length    string    `xml:"length,attr"`
+	Endianess string    `xml:"endianess,attr"`
+	Notes     string    `xml:"Notes"`
+	Consumers []nodeRef `xml:"Consumer>NodeRef"`
+	Value     *value    `xml:"Value"`
+	LabelSet  []label   `xml:"LabelSet>Label"`
+}
+
+type value struct {
+	Type      string `xml:"type,attr"`
+	Slope     string `xml:"slope,attr"`
+	Intercept string `xml:"intercept,attr"`
+	Unit      string `xml:"unit,attr"`
+	Min       string `xml:"min,attr"`
+	Max       string `xml:"max,attr"`
+}
+
+type label struct {
+	Name  string `xml:"name,attr"`
+	Value int64  `xml:"value,attr"`
+}
+
+type multiplex struct {
+	signal
+	MuxGroups []muxGroup `xml:"MuxGroup"`
+}
+
+type muxGroup struct {
+	Count   uint64   `xml:"count,attr"`
+	Signals []signal `xml:"Signal"`
+}
+
+// Parse reads a KCD network definition. The messages of every bus are
+// imported, their interval as the GenMsgCycleTime attribute.
+func Parse(r io.Reader) (*vera.Config, error) {
+	var definition networkDefinition
+	if err := xml.NewDecoder(r).Decode(&definition); err != nil {
+		return nil, fmt.Errorf("kcd: %w", err)
+	}
+
+	nodes := make(map[string]vera.Node, len(definition.Nodes))
+	config := &vera.Config{}
+	for _, n := range definition.Nodes {
+		nodes[n.ID] = vera.Node(n.Name)
+		config.Nodes = append(config.Nodes, vera.Node(n.Name))
+	}
+
+	hasCycleTimes := false
+	for _, b := range definition.Buses {
+		for _, m := range b.Messages {
+			message, err := convertMessage(m, nodes)
+			if err != nil {
+				return nil, fmt.Errorf("kcd: message '%s': %w", m.Name, err)
+			}
+			if m.Interval > 0 {
+				hasCycleTimes = true
+			}
+
+			config.Messages = append(config.Messages, *message)
+		}
+	}
+
+	if hasCycleTimes {
+		config.AttributeDefinitions = append(config.AttributeDefinitions, vera.AttributeDefinition{
+			Name:   "GenMsgCycleTime",
+			Object: vera.MessageAttribute,
+			Type:   vera.AttributeInt,
+		})
+	}
+
+	return config, nil
+}
+
+func convertMessage(m message, nodes map[string]vera.Node) (*vera.Message, error) {
+	id, err := strconv.ParseUint(m.ID, 0, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %s", m.ID)
+	}
+
+	message := &vera.Message{
+		Name:        m.Name,
+		ID:          uint32(id),
+		IsExtended:  m.Format == "extended",
+		Transmitter: "Vector__XXX",
+		Comment:     strings.TrimSpace(m.Notes),
+	}
+	if len(m.Producers) > 0 {
+		if message.Transmitter, err = lookupNode(nodes, m.Producers[0]); err != nil {
+			return nil, err
+		}
+	}
+	if m.Interval > 0 {
+		message.Attributes = vera.Attributes{"GenMsgCycleTime": int64(m.Interval)}
+	}
+
+	for _, s := range m.Signals {
+		signal, err := convertSignal(s, nodes)
+		if err != nil {
+			return nil, err
+		}
+		message.Signals = append(message.Signals, *signal)
+	}
+
+	for _, mux := range m.Multiplexes {
+		multiplexor, err := convertSignal(mux.signal, nodes)
+		if err != nil {
+			return nil, err
+		}
+		multiplexor.IsMultiplexor = true
+		message.Signals = append(message.Signals, *multiplexor)
+
+		for _, group := range mux.MuxGroups {
+			for _, s := range group.Signals {
+				signal, err := convertSignal(s, nodes)
+				if err != nil {
+					return nil, err
+				}
+				signal.Multiplexor = multiplexor.Name
+				signal.MultiplexValues = []vera.MultiplexRange{{Min: group.Count, Max: group.Count}}
+				message.Signals = append(message.Signals, *signal)
+			}
+		}
+	}
+
+	if m.Length == "" || m.Length == "auto" {
+		message.DLC = autoLength(message.Signals)
+	} else {
+		length, err := strconv.ParseUint(m.Length, 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid length: %s", m.Length)
+		}
+		message.DLC = uint8(length)
+	}
+
+	return message, nil
+}
+
+func convertSignal(s signal, nodes map[string]vera.Node) (*vera.Signal, error) {
+	length := uint64(1)
+	if s.Length != "" {
+		var err error
+		if length, err = strconv.ParseUint(s.Length, 10, 8); err != nil {
+			return nil, fmt.Errorf("signal '%s' has invalid length: %s", s.Name, s.Length)
+		}
+	}
+
+	signal := &vera.Signal{
+		Name:     s.Name,
+		StartBit: s.Offset,
+		Length:   uint8(length),
+		Factor:   1,
+		Comment:  strings.TrimSpace(s.Notes),
+	}
+
+	// KCD numbers the bits of big endian signals from the MSB of each
+	// byte, DBC from the LSB.
+	if s.Endianess == "big" {
+		signal.Endianness = vera.BigEndian
+		signal.StartBit = 8*(s.Offset/8) + 7 - s.Offset%8
+	}
+
+	for _, c := range s.Consumers {
+		receiver, err := lookupNode(nodes, c)
+		if err != nil {
+			return nil, err
+		}
+		signal.Receivers = append(signal.Receivers, receiver)
+	}
+
+	if len(s.LabelSet) > 0 {
+		signal.ValueDescriptions = make(map[int64]string, len(s.LabelSet))
+		for _, l := range s.LabelSet {
+			signal.ValueDescriptions[l.Value] = l.Name
+		}
+	}
+
+	if s.Value == nil {
+		return signal, nil
+	}
+
+	switch s.Value.Type {
+	case "signed":
+		signal.Signed = true
+	case "single":
+		signal.ValueType = vera.FloatValue
+	case "double":
+		signal.ValueType = vera.DoubleValue
+	case "", "unsigned":
+	default:
+		return nil, fmt.Errorf("signal '%s' has invalid type: %s", s.Name, s.Value.Type)
+	}
+
+	signal.Unit = s.Value.Unit
+	for _, f := range []struct {
+		attr  string
+		value *float32
+	}{
+		{s.Value.Slope, &signal.Factor},
+		{s.Value.Intercept, &signal.Offset},
+		{s.Value.Min, &signal.Min},
+		{s.Value.Max, &signal.Max},
+	} {
+		if f.attr == "" {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(f.attr, 32)
+		if err != nil {
+			return nil, fmt.Errorf("signal '%s' has invalid value: %s", s.Name, f.attr)
+		}
+		*f.value = float32(v)
+	}
+
+	return signal, nil
+}
+
+func lookupNode(nodes map[string]vera.Node, ref nodeRef) (vera.Node, error) {
+	n, ok := nodes[ref.ID]
+	if !ok {
+		return "", fmt.Errorf("unknown node id: %s", ref.ID)
+	}
+
+	return n, nil
+}
+
+// fdLengths are the payload sizes, in bytes, of CAN FD frames longer than
+// classic CAN ones.
+var fdLengths = []uint16{12, 16, 20, 24, 32, 48, 64}
+
+// autoLength returns the length, in bytes, of the shortest payload holding
+// the signals.
+func autoLength(signals []vera.Signal) uint8 {
+	var length uint16
+	for _, s := range signals {
+		var last uint16
+		if s.Endianness == vera.BigEndian {
+			// The LSB is Length-1 bits after the MSB, following the
+			// sawtooth numbering.
+			msb := 8*(s.StartBit/8) + 7 - s.StartBit%8
+			last = (msb + uint16(s.Length) - 1) / 8
+		} else {
+			last = (s.StartBit + uint16(s.Length) - 1) / 8
+		}
+
+		length = max(length, last+1)
+	}
+
+	if length > 8 {
+		for _, fdLength := range fdLengths {
+			if fdLength >= length {
+				return uint8(fdLength)
+			}
+		}
+	}
+
+	return uint8(length)
+}
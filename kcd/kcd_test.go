@@ -0,0 +1,86 @@
+package kcd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ApexCorse/vera"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should import the network of a KCD file", func(t *testing.T) {
+		a := assert.New(t)
+
+		file, err := os.Open("testdata/network.kcd")
+		a.Nil(err)
+		defer file.Close()
+
+		config, err := vera.ParseWith(file, vera.ParseOptions{File: "network.kcd", Importer: Parse})
+		a.Nil(err)
+		a.Nil(config.Validate())
+
+		expected, err := os.ReadFile("testdata/network.dbc")
+		a.Nil(err)
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+		a.Equal(string(expected), b.String())
+	})
+
+	t.Run("should import multiplexed signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		file, err := os.Open("testdata/network.kcd")
+		a.Nil(err)
+		defer file.Close()
+
+		config, err := Parse(file)
+		a.Nil(err)
+
+		diagnostics := config.Messages[2]
+		a.True(diagnostics.Signals[0].IsMultiplexor)
+		a.Equal("Service", diagnostics.Signals[1].Multiplexor)
+		a.Equal([]vera.MultiplexRange{{Min: 2, Max: 2}}, diagnostics.Signals[2].MultiplexValues)
+	})
+
+	t.Run("should size messages of length auto", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`<NetworkDefinition><Bus>
+			<Message id="0x1" name="Short" length="auto"><Signal name="A" offset="4" length="12"/></Message>
+			<Message id="0x2" name="Big"><Signal name="B" offset="8" length="16" endianess="big"/></Message>
+			<Message id="0x3" name="Fd"><Signal name="C" offset="64" length="16"/></Message>
+		</Bus></NetworkDefinition>`))
+		a.Nil(err)
+		a.Equal(uint8(2), config.Messages[0].DLC)
+		a.Equal(uint8(3), config.Messages[1].DLC)
+		a.Equal(uint8(12), config.Messages[2].DLC)
+	})
+
+	t.Run("should return error for unknown nodes", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`<NetworkDefinition><Bus>
+			<Message id="0x1" name="Status"><Producer><NodeRef id="9"/></Producer></Message>
+		</Bus></NetworkDefinition>`))
+		a.EqualError(err, "kcd: message 'Status': unknown node id: 9")
+	})
+
+	t.Run("should return error for invalid values", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`<NetworkDefinition><Bus>
+			<Message id="0x1" name="Status"><Signal name="A" offset="0" length="8"><Value slope="fast"/></Signal></Message>
+		</Bus></NetworkDefinition>`))
+		a.EqualError(err, "kcd: message 'Status': signal 'A' has invalid value: fast")
+	})
+
+	t.Run("should return error for invalid XML", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`<NetworkDefinition>`))
+		a.NotNil(err)
+	})
+}
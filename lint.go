@@ -0,0 +1,325 @@
+package vera
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+)
+
+// LintRule is a check for configurations that are valid, but most likely
+// not what their authors meant.
+type LintRule struct {
+	// ID identifies the rule in LintConfig, and is the Code of the
+	// diagnostics it reports.
+	ID          string
+	Description string
+	// Severity is the severity of the diagnostics reported by the rule,
+	// unless LintConfig changes it.
+	Severity Severity
+
+	check func(c *Config) []Diagnostic
+}
+
+// LintRules are the rules run by Config.Lint, in order.
+var LintRules = []LintRule{
+	{
+		ID:          "unrepresentable-range",
+		Description: "signal [min|max] range cannot be represented in its bits at its factor and offset",
+		Severity:    SeverityWarning,
+		check:       lintUnrepresentableRanges,
+	},
+	{
+		ID:          "unknown-topic-signal",
+		Description: "TP_ topic refers to a signal no message has",
+		Severity:    SeverityWarning,
+		check:       lintUnknownTopicSignals,
+	},
+	{
+		ID:          "empty-message",
+		Description: "message has no signals",
+		Severity:    SeverityWarning,
+		check:       lintEmptyMessages,
+	},
+	{
+		ID:          "non-c-identifier",
+		Description: "message, signal or node name is not a valid C identifier",
+		Severity:    SeverityError,
+		check:       lintNonCIdentifiers,
+	},
+	{
+		ID:          "duplicate-message-name",
+		Description: "several messages have the same name",
+		Severity:    SeverityError,
+		check:       lintDuplicateMessageNames,
+	},
+	{
+		ID:          "duplicate-message-id",
+		Description: "several messages have the same ID",
+		Severity:    SeverityError,
+		check:       lintDuplicateMessageIDs,
+	},
+	{
+		ID:          "duplicate-signal-name",
+		Description: "signals of several messages have the same name, which TP_ topics cannot tell apart",
+		Severity:    SeverityWarning,
+		check:       lintDuplicateSignalNames,
+	},
+}
+
+// Values of LintConfig.Rules.
+const (
+	LintOff     = "off"
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// LintConfig selects the rules run by Config.Lint.
+type LintConfig struct {
+	// Rules maps rule IDs to "off", "error" or "warning". Rules that are
+	// not listed run with their default severity.
+	Rules map[string]string `json:"rules"`
+}
+
+// ParseLintConfig reads a JSON lint configuration such as:
+//
+//	{"rules": {"empty-message": "off", "duplicate-signal-name": "error"}}
+func ParseLintConfig(r io.Reader) (*LintConfig, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	config := &LintConfig{}
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("lint config: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (lc *LintConfig) Validate() error {
+	for id, value := range lc.Rules {
+		if lintRule(id) == nil {
+			return fmt.Errorf("lint config: unknown rule: %s", id)
+		}
+
+		switch value {
+		case LintOff, LintError, LintWarning:
+		default:
+			return fmt.Errorf("lint config: rule %s must be one of off, error and warning: %s", id, value)
+		}
+	}
+
+	return nil
+}
+
+// Lint runs LintRules on the configuration, as selected by lc. A nil lc
+// runs every rule with its default severity.
+func (c *Config) Lint(lc *LintConfig) Diagnostics {
+	var diagnostics Diagnostics
+	for _, rule := range LintRules {
+		severity := rule.Severity
+		if lc != nil {
+			switch lc.Rules[rule.ID] {
+			case LintOff:
+				continue
+			case LintError:
+				severity = SeverityError
+			case LintWarning:
+				severity = SeverityWarning
+			}
+		}
+
+		for _, d := range rule.check(c) {
+			d.File = c.file
+			d.Severity = severity
+			d.Code = rule.ID
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	return diagnostics
+}
+
+func lintRule(id string) *LintRule {
+	for i := range LintRules {
+		if LintRules[i].ID == id {
+			return &LintRules[i]
+		}
+	}
+
+	return nil
+}
+
+// lintAtLine returns a diagnostic at a 0-based line index, as errorAtLine.
+func lintAtLine(lineNumber int, format string, a ...any) Diagnostic {
+	return Diagnostic{
+		Line:    lineNumber + 1,
+		Message: fmt.Sprintf(format, a...),
+	}
+}
+
+func lintUnrepresentableRanges(c *Config) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, m := range c.Messages {
+		for _, s := range m.Signals {
+			// [0|0] is how DBC files leave the range out, and IEEE
+			// signals can hold any range.
+			if s.Min == 0 && s.Max == 0 || s.ValueType != IntegerValue || s.Length == 0 {
+				continue
+			}
+
+			if s.Min > s.Max {
+				diagnostics = append(diagnostics, lintAtLine(s.lineNumber, "signal '%s' minimum %g is greater than its maximum %g", s.Name, s.Min, s.Max))
+				continue
+			}
+
+			low, high := s.physicalBounds()
+			// Half a step of slack, for ranges rounded when written, and
+			// compared as float32 as they are parsed.
+			slack := math.Abs(float64(s.Factor)) / 2
+			if s.Min < float32(low-slack) || s.Max > float32(high+slack) {
+				diagnostics = append(diagnostics, lintAtLine(s.lineNumber, "signal '%s' range [%g|%g] cannot be represented in %d bits, which hold [%g|%g]", s.Name, s.Min, s.Max, s.Length, float32(low), float32(high)))
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+// physicalBounds returns the lowest and highest physical values the raw
+// bits of an integer signal can hold.
+func (s *Signal) physicalBounds() (low, high float64) {
+	var rawLow, rawHigh float64
+	if s.Signed {
+		rawLow = -math.Exp2(float64(s.Length - 1))
+		rawHigh = math.Exp2(float64(s.Length-1)) - 1
+	} else {
+		rawHigh = math.Exp2(float64(s.Length)) - 1
+	}
+
+	low = rawLow*float64(s.Factor) + float64(s.Offset)
+	high = rawHigh*float64(s.Factor) + float64(s.Offset)
+	if low > high {
+		low, high = high, low
+	}
+
+	return low, high
+}
+
+func lintUnknownTopicSignals(c *Config) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, t := range c.Topics {
+		if t.Signal == "" || c.hasSignal(t.Signal) {
+			continue
+		}
+
+		diagnostics = append(diagnostics, lintAtLine(t.lineNumber, "topic '%s' refers to unknown signal '%s'", t.Topic, t.Signal))
+	}
+
+	return diagnostics
+}
+
+func (c *Config) hasSignal(name string) bool {
+	for i := range c.Messages {
+		if c.Messages[i].Signal(name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func lintEmptyMessages(c *Config) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, m := range c.Messages {
+		if len(m.Signals) == 0 {
+			diagnostics = append(diagnostics, lintAtLine(m.lineNumber, "message '%s' has no signals", m.Name))
+		}
+	}
+
+	return diagnostics
+}
+
+var cIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func lintNonCIdentifiers(c *Config) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, n := range c.Nodes {
+		if !cIdentifierRegexp.MatchString(string(n)) {
+			diagnostics = append(diagnostics, Diagnostic{Message: fmt.Sprintf("node '%s' is not a valid C identifier", n)})
+		}
+	}
+
+	for _, m := range c.Messages {
+		if !cIdentifierRegexp.MatchString(m.Name) {
+			diagnostics = append(diagnostics, lintAtLine(m.lineNumber, "message '%s' is not a valid C identifier", m.Name))
+		}
+
+		for _, s := range m.Signals {
+			if !cIdentifierRegexp.MatchString(s.Name) {
+				diagnostics = append(diagnostics, lintAtLine(s.lineNumber, "signal '%s' is not a valid C identifier", s.Name))
+			}
+		}
+	}
+
+	return diagnostics
+}
+
+func lintDuplicateMessageNames(c *Config) []Diagnostic {
+	var diagnostics []Diagnostic
+	first := make(map[string]*Message)
+	for i := range c.Messages {
+		m := &c.Messages[i]
+		if previous, ok := first[m.Name]; ok {
+			diagnostics = append(diagnostics, lintAtLine(m.lineNumber, "message name '%s' is already used on line %d", m.Name, previous.lineNumber+1))
+			continue
+		}
+		first[m.Name] = m
+	}
+
+	return diagnostics
+}
+
+func lintDuplicateMessageIDs(c *Config) []Diagnostic {
+	type frameID struct {
+		id       uint32
+		extended bool
+	}
+
+	var diagnostics []Diagnostic
+	first := make(map[frameID]*Message)
+	for i := range c.Messages {
+		m := &c.Messages[i]
+		key := frameID{m.ID, m.IsExtended}
+		if previous, ok := first[key]; ok {
+			diagnostics = append(diagnostics, lintAtLine(m.lineNumber, "message '%s' ID %#x is already used by message '%s'", m.Name, m.ID, previous.Name))
+			continue
+		}
+		first[key] = m
+	}
+
+	return diagnostics
+}
+
+func lintDuplicateSignalNames(c *Config) []Diagnostic {
+	var diagnostics []Diagnostic
+	first := make(map[string]string)
+	for _, m := range c.Messages {
+		for _, s := range m.Signals {
+			message, ok := first[s.Name]
+			if !ok {
+				first[s.Name] = m.Name
+				continue
+			}
+
+			diagnostics = append(diagnostics, lintAtLine(s.lineNumber, "signal '%s' of message '%s' is already in message '%s'", s.Name, m.Name, message))
+		}
+	}
+
+	return diagnostics
+}
@@ -0,0 +1,195 @@
+package vera
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const lintConfig = `VERSION ""
+
+BU_: Engine Driver-Gateway
+
+BO_ 123 EngineSpeed: 4 Engine
+	SG_ EngineSpeed : 0|8@1+ (0.1,0) [0|100] "RPM" Driver-Gateway
+	SG_ OilTemperature : 8|8@1- (1,0) [-40|150] "ºC" Driver-Gateway
+
+BO_ 123 EngineSpeed: 1 Engine
+
+BO_ 124 Status: 2 Engine
+	SG_ EngineSpeed : 0|16@1+ (1,0) [0|0] ""
+	SG_ Torque : 0|32@1+ (1,0) [0|4294967295] "Nm"
+
+TP_ EngineSpeed vehicle/engine/speed
+TP_ Gear vehicle/gear
+`
+
+func lintCodes(diagnostics Diagnostics) map[string][]int {
+	codes := make(map[string][]int)
+	for _, d := range diagnostics {
+		codes[d.Code] = append(codes[d.Code], d.Line)
+	}
+
+	return codes
+}
+
+func TestConfigLint(t *testing.T) {
+	t.Run("should report every rule with its default severity", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := ParseWith(strings.NewReader(lintConfig), ParseOptions{File: "lint.dbc"})
+		a.Nil(err)
+
+		diagnostics := config.Lint(nil)
+		a.Equal(map[string][]int{
+			"unrepresentable-range":  {6, 7},
+			"unknown-topic-signal":   {16},
+			"empty-message":          {9},
+			"non-c-identifier":       {0},
+			"duplicate-message-name": {9},
+			"duplicate-message-id":   {9},
+			"duplicate-signal-name":  {12},
+		}, lintCodes(diagnostics))
+		a.True(diagnostics.HasErrors())
+
+		for _, d := range diagnostics {
+			a.Equal("lint.dbc", d.File)
+			a.Equal(lintRule(d.Code).Severity, d.Severity)
+		}
+	})
+
+	t.Run("should not report representable ranges", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := &Config{
+			Messages: []Message{
+				{
+					Name: "Speed",
+					Signals: []Signal{
+						{Name: "Unsigned", Length: 8, Factor: 0.5, Offset: -10, Min: -10, Max: 117.5},
+						{Name: "Signed", Length: 8, Signed: true, Factor: 1, Min: -128, Max: 127},
+						{Name: "Negative", Length: 8, Factor: -1, Min: -255, Max: 0},
+						{Name: "Rounded", Length: 16, Factor: 0.001, Min: 0, Max: 65.535},
+						{Name: "Float", Length: 32, ValueType: FloatValue, Factor: 1, Min: -1e10, Max: 1e10},
+					},
+				},
+			},
+		}
+
+		a.Empty(lintCodes(config.Lint(nil))["unrepresentable-range"])
+	})
+
+	t.Run("should report ranges with min greater than max", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := &Config{
+			Messages: []Message{
+				{
+					Name:    "Speed",
+					Signals: []Signal{{Name: "Speed", Length: 8, Factor: 1, Min: 10, Max: 5}},
+				},
+			},
+		}
+
+		diagnostics := config.Lint(nil)
+		a.Len(diagnostics, 1)
+		a.Equal("unrepresentable-range", diagnostics[0].Code)
+		a.Contains(diagnostics[0].Message, "greater than its maximum")
+	})
+
+	t.Run("should turn rules off and change their severity", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(lintConfig))
+		a.Nil(err)
+
+		diagnostics := config.Lint(&LintConfig{Rules: map[string]string{
+			"non-c-identifier":       LintOff,
+			"duplicate-message-name": LintOff,
+			"duplicate-message-id":   LintWarning,
+			"empty-message":          LintError,
+		}})
+
+		codes := lintCodes(diagnostics)
+		a.NotContains(codes, "non-c-identifier")
+		a.NotContains(codes, "duplicate-message-name")
+		for _, d := range diagnostics {
+			switch d.Code {
+			case "duplicate-message-id":
+				a.Equal(SeverityWarning, d.Severity)
+			case "empty-message":
+				a.Equal(SeverityError, d.Severity)
+			}
+		}
+	})
+}
+
+func TestParseLintConfig(t *testing.T) {
+	t.Run("should parse lint config", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := ParseLintConfig(strings.NewReader(`{"rules": {"empty-message": "off", "duplicate-signal-name": "error"}}`))
+		a.Nil(err)
+		a.Equal(map[string]string{"empty-message": "off", "duplicate-signal-name": "error"}, config.Rules)
+	})
+
+	t.Run("should return error for unknown rule", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := ParseLintConfig(strings.NewReader(`{"rules": {"no-such-rule": "off"}}`))
+		a.EqualError(err, "lint config: unknown rule: no-such-rule")
+	})
+
+	t.Run("should return error for invalid severity", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := ParseLintConfig(strings.NewReader(`{"rules": {"empty-message": "info"}}`))
+		a.EqualError(err, "lint config: rule empty-message must be one of off, error and warning: info")
+	})
+
+	t.Run("should return error for unknown fields", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := ParseLintConfig(strings.NewReader(`{"rule": {}}`))
+		a.NotNil(err)
+	})
+}
+
+func TestWriteSARIF(t *testing.T) {
+	t.Run("should write diagnostics as SARIF results", func(t *testing.T) {
+		a := assert.New(t)
+
+		diagnostics := Diagnostics{
+			{File: "vera.dbc", Line: 5, Column: 2, Severity: SeverityWarning, Code: "empty-message", Message: "message 'Status' has no signals"},
+			{File: "vera.dbc", Severity: SeverityError, Code: "non-c-identifier", Message: "node 'A-B' is not a valid C identifier"},
+		}
+
+		var b strings.Builder
+		a.Nil(WriteSARIF(&b, diagnostics))
+
+		var log map[string]any
+		a.Nil(json.Unmarshal([]byte(b.String()), &log))
+		a.Equal("2.1.0", log["version"])
+
+		run := log["runs"].([]any)[0].(map[string]any)
+		driver := run["tool"].(map[string]any)["driver"].(map[string]any)
+		a.Equal("vera", driver["name"])
+		a.Len(driver["rules"], len(LintRules))
+
+		results := run["results"].([]any)
+		a.Len(results, 2)
+
+		first := results[0].(map[string]any)
+		a.Equal("empty-message", first["ruleId"])
+		a.Equal("warning", first["level"])
+		location := first["locations"].([]any)[0].(map[string]any)["physicalLocation"].(map[string]any)
+		a.Equal("vera.dbc", location["artifactLocation"].(map[string]any)["uri"])
+		a.Equal(map[string]any{"startLine": float64(5), "startColumn": float64(2)}, location["region"])
+
+		second := results[1].(map[string]any)
+		a.Equal("error", second["level"])
+		a.NotContains(second["locations"].([]any)[0].(map[string]any)["physicalLocation"], "region")
+	})
+}
@@ -0,0 +1,213 @@
+package vera
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Merge joins the networks of several configurations, such as the DBC files
+// of the subsystems sharing a bus, into one. The configurations are expected
+// to be valid on their own, and are left untouched.
+//
+// Messages, topics and attribute definitions found in several of them are
+// merged if they are the same, and reported as conflicts otherwise: two
+// messages with the same ID or the same name, the same signal with
+// different topics, and different attribute definitions or values with the
+// same name. Once merged, every node used by a message must be declared by
+// some configuration, if any declares nodes. Conflicts are returned as
+// Diagnostics, with the merged configuration.
+func Merge(configs ...*Config) (*Config, error) {
+	m := merger{merged: &Config{}}
+	for _, c := range configs {
+		m.mergeNodes(c)
+	}
+	for _, c := range configs {
+		m.mergeMessages(c)
+		m.mergeTopics(c)
+		m.mergeAttributes(c)
+		m.mergeComments(c)
+	}
+	for _, c := range configs {
+		m.checkNodes(c)
+	}
+	m.setTopics()
+
+	return m.merged, m.diagnostics.Err()
+}
+
+type merger struct {
+	merged      *Config
+	diagnostics Diagnostics
+
+	// messageFiles and topicFiles are the files each merged message and
+	// topic come from, for the diagnostics.
+	messageFiles []string
+	topicFiles   []string
+}
+
+func (m *merger) conflict(file string, lineNumber int, format string, a ...any) {
+	m.diagnostics.add(errorAtLine(lineNumber, format, a...), CodeMergeConflict, file)
+}
+
+func (m *merger) mergeNodes(c *Config) {
+	for _, n := range c.Nodes {
+		if !slices.Contains(m.merged.Nodes, n) {
+			m.merged.Nodes = append(m.merged.Nodes, n)
+		}
+	}
+}
+
+func (m *merger) mergeMessages(c *Config) {
+	for _, message := range c.Messages {
+		i := slices.IndexFunc(m.merged.Messages, func(merged Message) bool {
+			return sameFrameID(&merged, &message) || merged.Name == message.Name
+		})
+		if i < 0 {
+			message.Signals = slices.Clone(message.Signals)
+			m.merged.Messages = append(m.merged.Messages, message)
+			m.messageFiles = append(m.messageFiles, c.file)
+			continue
+		}
+
+		merged := &m.merged.Messages[i]
+		switch {
+		case !sameFrameID(merged, &message):
+			m.conflict(c.file, message.lineNumber, "message name '%s' is already used by ID %s in %s", message.Name, formatFrameID(merged), describeFile(m.messageFiles[i]))
+		case merged.Name != message.Name:
+			m.conflict(c.file, message.lineNumber, "message '%s' ID %s is already used by message '%s' in %s", message.Name, formatFrameID(&message), merged.Name, describeFile(m.messageFiles[i]))
+		case !reflect.DeepEqual(messageDefinition(*merged), messageDefinition(message)):
+			m.conflict(c.file, message.lineNumber, "message '%s' is defined differently in %s", message.Name, describeFile(m.messageFiles[i]))
+		}
+	}
+}
+
+func (m *merger) mergeTopics(c *Config) {
+	for _, t := range c.Topics {
+		i := slices.IndexFunc(m.merged.Topics, func(merged SignalTopic) bool {
+			return merged.Signal == t.Signal
+		})
+		if i < 0 {
+			m.merged.Topics = append(m.merged.Topics, t)
+			m.topicFiles = append(m.topicFiles, c.file)
+			continue
+		}
+
+		if m.merged.Topics[i].Topic != t.Topic {
+			m.conflict(c.file, t.lineNumber, "topic %s of signal '%s' conflicts with %s in %s", t.Topic, t.Signal, m.merged.Topics[i].Topic, describeFile(m.topicFiles[i]))
+		}
+	}
+}
+
+func (m *merger) mergeAttributes(c *Config) {
+	for _, d := range c.AttributeDefinitions {
+		merged := m.merged.AttributeDefinition(d.Name)
+		if merged == nil {
+			m.merged.AttributeDefinitions = append(m.merged.AttributeDefinitions, d)
+			continue
+		}
+
+		definition := *merged
+		definition.lineNumber, d.lineNumber = 0, 0
+		if !reflect.DeepEqual(definition, d) {
+			m.conflict(c.file, -1, "attribute '%s' is defined differently in another file", d.Name)
+		}
+	}
+
+	var err error
+	if m.merged.Attributes, err = mergeAttributeValues(m.merged.Attributes, c.Attributes); err != nil {
+		m.conflict(c.file, -1, "network %s", err.Error())
+	}
+
+	nodes := slices.Sorted(maps.Keys(c.NodeAttributes))
+	for _, n := range nodes {
+		if m.merged.NodeAttributes == nil {
+			m.merged.NodeAttributes = make(map[Node]Attributes)
+		}
+
+		if m.merged.NodeAttributes[n], err = mergeAttributeValues(m.merged.NodeAttributes[n], c.NodeAttributes[n]); err != nil {
+			m.conflict(c.file, -1, "node '%s' %s", n, err.Error())
+		}
+	}
+}
+
+// mergeAttributeValues returns the union of two sets of attributes, or an
+// error if they give an attribute different values.
+func mergeAttributeValues(merged, attributes Attributes) (Attributes, error) {
+	if len(attributes) == 0 {
+		return merged, nil
+	}
+
+	if merged == nil {
+		merged = make(Attributes, len(attributes))
+	}
+	for _, name := range slices.Sorted(maps.Keys(attributes)) {
+		value, ok := merged[name]
+		if ok && value != attributes[name] {
+			return merged, fmt.Errorf("attribute '%s' is %v in another file", name, value)
+		}
+		merged[name] = attributes[name]
+	}
+
+	return merged, nil
+}
+
+// mergeComments keeps the first comment of the network and of each node.
+func (m *merger) mergeComments(c *Config) {
+	if m.merged.Comment == "" {
+		m.merged.Comment = c.Comment
+	}
+
+	for n, comment := range c.NodeComments {
+		if m.merged.NodeComments == nil {
+			m.merged.NodeComments = make(map[Node]string)
+		}
+		if _, ok := m.merged.NodeComments[n]; !ok {
+			m.merged.NodeComments[n] = comment
+		}
+	}
+}
+
+// setTopics sets the merged topics on the merged signals, as
+// Config.Validate does.
+func (m *merger) setTopics() {
+	topics := topicsBySignal(m.merged)
+	for i := range m.merged.Messages {
+		for j := range m.merged.Messages[i].Signals {
+			m.merged.Messages[i].Signals[j].Topic = topics[m.merged.Messages[i].Signals[j].Name]
+		}
+	}
+}
+
+// checkNodes checks that the transmitters and receivers of the messages of
+// c are declared by some configuration, as Config.Validate does for each.
+func (m *merger) checkNodes(c *Config) {
+	for i := range c.Messages {
+		for _, err := range m.merged.validateNodes(&c.Messages[i]) {
+			m.diagnostics.add(err, CodeUndeclaredNode, c.file)
+		}
+	}
+}
+
+// messageDefinition returns a copy of the message for comparisons, without
+// the line numbers of its definition and the topics of its signals, which
+// are merged separately.
+func messageDefinition(message Message) Message {
+	message.lineNumber = 0
+	message.Signals = slices.Clone(message.Signals)
+	for i := range message.Signals {
+		message.Signals[i].lineNumber = 0
+		message.Signals[i].Topic = ""
+	}
+
+	return message
+}
+
+func describeFile(file string) string {
+	if file == "" {
+		return "another file"
+	}
+
+	return file
+}
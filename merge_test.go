@@ -0,0 +1,185 @@
+package vera
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const powertrainConfig = `VERSION ""
+
+BU_: Engine Dashboard
+
+BO_ 123 EngineSpeed: 2 Engine
+	SG_ Speed : 0|16@1+ (0.1,0) [0|6000] "RPM" Dashboard
+
+BO_ 300 Heartbeat: 1 Dashboard
+	SG_ Alive : 0|1@1+ (1,0) [0|1] "" Engine
+
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_ "GenMsgCycleTime" BO_ 123 10;
+
+TP_ Speed vehicle/engine/speed
+`
+
+const chassisConfig = `VERSION ""
+
+BU_: Brakes Dashboard
+
+BO_ 200 BrakePressure: 1 Brakes
+	SG_ Pressure : 0|8@1+ (0.5,0) [0|127.5] "bar" Dashboard
+
+BO_ 300 Heartbeat: 1 Dashboard
+	SG_ Alive : 0|1@1+ (1,0) [0|1] "" Engine
+
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+
+TP_ Pressure vehicle/brakes/pressure
+`
+
+func parseMergeConfig(t *testing.T, file, dbc string) *Config {
+	config, err := ParseWith(strings.NewReader(dbc), ParseOptions{File: file})
+	assert.Nil(t, err)
+
+	return config
+}
+
+func mergeDiagnostics(t *testing.T, err error) Diagnostics {
+	var diagnostics Diagnostics
+	assert.True(t, errors.As(err, &diagnostics))
+
+	return diagnostics
+}
+
+func TestMerge(t *testing.T) {
+	t.Run("should merge networks", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig)
+		chassis := parseMergeConfig(t, "chassis.dbc", chassisConfig)
+
+		merged, err := Merge(powertrain, chassis)
+		a.Nil(err)
+		a.Equal([]Node{"Engine", "Dashboard", "Brakes"}, merged.Nodes)
+
+		var names []string
+		for _, m := range merged.Messages {
+			names = append(names, m.Name)
+		}
+		a.Equal([]string{"EngineSpeed", "Heartbeat", "BrakePressure"}, names)
+
+		a.Len(merged.AttributeDefinitions, 1)
+		a.Equal(uint32(10), merged.Messages[0].CycleTime())
+		a.Len(merged.Topics, 2)
+		a.Equal("vehicle/engine/speed", merged.Messages[0].Signals[0].Topic)
+		a.Equal("vehicle/brakes/pressure", merged.Messages[2].Signals[0].Topic)
+		a.Nil(merged.Validate())
+	})
+
+	t.Run("should leave the merged configurations untouched", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig)
+		chassis := parseMergeConfig(t, "chassis.dbc", chassisConfig)
+
+		merged, err := Merge(powertrain, chassis)
+		a.Nil(err)
+
+		merged.Messages[0].Signals[0].Name = "Renamed"
+		a.Equal("Speed", powertrain.Messages[0].Signals[0].Name)
+		a.Len(powertrain.Messages, 2)
+	})
+
+	t.Run("should report message ID collisions", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig)
+		chassis := parseMergeConfig(t, "chassis.dbc", strings.Replace(chassisConfig, "BO_ 200", "BO_ 123", 1))
+
+		_, err := Merge(powertrain, chassis)
+		diagnostics := mergeDiagnostics(t, err)
+		a.Len(diagnostics, 1)
+		a.Equal(Diagnostic{
+			File:     "chassis.dbc",
+			Line:     5,
+			Severity: SeverityError,
+			Code:     CodeMergeConflict,
+			Message:  "message 'BrakePressure' ID 0x7b is already used by message 'EngineSpeed' in powertrain.dbc",
+		}, diagnostics[0])
+	})
+
+	t.Run("should report message name collisions", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig)
+		chassis := parseMergeConfig(t, "chassis.dbc", strings.Replace(chassisConfig, "BrakePressure", "EngineSpeed", 1))
+
+		_, err := Merge(powertrain, chassis)
+		diagnostics := mergeDiagnostics(t, err)
+		a.Len(diagnostics, 1)
+		a.Equal("chassis.dbc:5: message name 'EngineSpeed' is already used by ID 0x7b in powertrain.dbc", diagnostics[0].Error())
+	})
+
+	t.Run("should report messages defined differently", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig)
+		chassis := parseMergeConfig(t, "chassis.dbc", strings.Replace(chassisConfig, "SG_ Alive : 0|1@1+", "SG_ Alive : 1|1@1+", 1))
+
+		_, err := Merge(powertrain, chassis)
+		diagnostics := mergeDiagnostics(t, err)
+		a.Len(diagnostics, 1)
+		a.Equal("chassis.dbc:8: message 'Heartbeat' is defined differently in powertrain.dbc", diagnostics[0].Error())
+	})
+
+	t.Run("should report conflicting topics", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig)
+		chassis := parseMergeConfig(t, "chassis.dbc", chassisConfig+"TP_ Speed vehicle/speed\n")
+
+		_, err := Merge(powertrain, chassis)
+		diagnostics := mergeDiagnostics(t, err)
+		a.Len(diagnostics, 1)
+		a.Equal("chassis.dbc:14: topic vehicle/speed of signal 'Speed' conflicts with vehicle/engine/speed in powertrain.dbc", diagnostics[0].Error())
+	})
+
+	t.Run("should report conflicting attributes", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig+`BA_DEF_ "BusType" STRING;
+BA_ "BusType" "CAN";
+`)
+		chassis := parseMergeConfig(t, "chassis.dbc", strings.Replace(chassisConfig, "INT 0 10000", "INT 0 1000", 1)+`BA_DEF_ "BusType" STRING;
+BA_ "BusType" "CAN FD";
+`)
+
+		_, err := Merge(powertrain, chassis)
+		diagnostics := mergeDiagnostics(t, err)
+		a.Len(diagnostics, 2)
+		a.Equal("chassis.dbc: attribute 'GenMsgCycleTime' is defined differently in another file", diagnostics[0].Error())
+		a.Equal("chassis.dbc: network attribute 'BusType' is CAN in another file", diagnostics[1].Error())
+	})
+
+	t.Run("should report nodes no configuration declares", func(t *testing.T) {
+		a := assert.New(t)
+
+		powertrain := parseMergeConfig(t, "powertrain.dbc", powertrainConfig)
+		telemetry := parseMergeConfig(t, "telemetry.dbc", `BO_ 400 Lap: 1 Logger
+	SG_ Number : 0|8@1+ (1,0) [0|255] "" Dashboard
+`)
+
+		_, err := Merge(powertrain, telemetry)
+		diagnostics := mergeDiagnostics(t, err)
+		a.Len(diagnostics, 1)
+		a.Equal(Diagnostic{
+			File:     "telemetry.dbc",
+			Line:     1,
+			Severity: SeverityError,
+			Code:     CodeUndeclaredNode,
+			Message:  "message transmitter 'Logger' is not declared in BU_",
+		}, diagnostics[0])
+	})
+}
@@ -1,95 +1,301 @@
 package vera
 
 import (
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 )
 
+// maxDataLength is the payload size, in bytes, of the largest CAN FD frame.
+const maxDataLength = 64
+
+// fdDataLengths are the payload sizes, in bytes, that CAN FD adds to the
+// 0 to 8 bytes of classic CAN.
+var fdDataLengths = []uint8{12, 16, 20, 24, 32, 48, 64}
+
+const (
+	// extendedIDFlag is set on the ID of BO_ lines of extended frames.
+	extendedIDFlag = 1 << 31
+
+	maxStandardID = 0x7FF
+	maxExtendedID = 0x1FFFFFFF
+)
+
 type Message struct {
-	Name        string
+	Name string
+	// ID is the 11 bits identifier of the message, or the 29 bits one
+	// when IsExtended is set.
 	ID          uint32
+	IsExtended  bool
 	DLC         uint8
 	Transmitter Node
-	Signals     []Signal
+	// Comment is the CM_ BO_ comment of the message.
+	Comment    string
+	Attributes Attributes
+	Signals    []Signal
 
-	signalsTotalLength  uint8
-	lineNumber          int
-	signalsBitPositions [64]int
+	signalsTotalLength uint16
+	lineNumber         int
 }
 
+// Validate checks the message and its signals, returning Diagnostics with
+// every problem found.
 func (m *Message) Validate() error {
-	if m.DLC > 8 {
-		return errorAtLine(m.lineNumber, "message DLC must be a number between 1 and 8")
+	var diagnostics Diagnostics
+	for _, err := range m.validate() {
+		diagnostics.add(err, CodeInvalidMessage, "")
 	}
 
-	//TODO(lentscode): check for start bits out of bounds
-	if m.signalsTotalLength > m.DLC*8 {
-		return errorAtLine(m.lineNumber, "sum of signal lengths must be less than or equal to (message DLC * 8)")
+	return diagnostics.Err()
+}
+
+// validate returns every problem of the message and its signals, the ones
+// of the signals with CodeInvalidSignal.
+func (m *Message) validate() []error {
+	var errs []error
+	if m.IsExtended && m.ID > maxExtendedID {
+		errs = append(errs, errorAtLine(m.lineNumber, "extended message ID must be a number between 0x0 and %#x", maxExtendedID))
+	}
+	if !m.IsExtended && m.ID > maxStandardID {
+		errs = append(errs, errorAtLine(m.lineNumber, "standard message ID must be a number between 0x0 and %#x", maxStandardID))
 	}
 
-	current := -1
-	for _, p := range m.signalsBitPositions {
-		if p == -1 {
-			continue
-		}
+	if m.DLC > 8 && !slices.Contains(fdDataLengths, m.DLC) {
+		errs = append(errs, errorAtLine(m.lineNumber, "message DLC must be a number between 1 and 8, or one of 12, 16, 20, 24, 32, 48 and 64 for CAN FD"))
+	}
 
-		if current == -1 {
-			current = p
-			continue
-		}
+	if m.signalsTotalLength > uint16(m.DLC)*8 {
+		errs = append(errs, errorAtLine(m.lineNumber, "sum of signal lengths must be less than or equal to (message DLC * 8)"))
+	}
 
-		if current != p {
-			return errorAtLine(m.lineNumber, "signals '%s' and '%s' cannot overlap", m.Signals[current].Name, m.Signals[p].Name)
+	for i := range m.Signals {
+		if err := m.validateMultiplexing(&m.Signals[i]); err != nil {
+			errs = append(errs, err)
 		}
-
-		current = -1
 	}
 
-	if current != -1 {
-		return errorAtLine(m.lineNumber, "signal '%s' gives problems", m.Signals[current].Name)
-	}
+	errs = append(errs, m.validatePlacement()...)
 
 	for _, s := range m.Signals {
 		if err := s.Validate(); err != nil {
-			return err
+			errs = append(errs, withCode(err, CodeInvalidSignal))
 		}
 	}
 
-	return nil
+	return errs
+}
+
+// validatePlacement checks that every signal fits in the payload, and
+// overlaps no other signal that can be present in the same frame. Each
+// pair of overlapping signals is reported once.
+func (m *Message) validatePlacement() []error {
+	var errs []error
+	bitOwners := make([][]int, int(m.DLC)*8)
+	overlaps := make(map[[2]int]bool)
+	for i := range m.Signals {
+		for _, p := range m.Signals[i].bitPositions() {
+			if p >= len(bitOwners) {
+				errs = append(errs, errorAtLine(m.lineNumber, "signal '%s' does not fit in the message payload", m.Signals[i].Name))
+				break
+			}
+
+			for _, owner := range bitOwners[p] {
+				if overlaps[[2]int{owner, i}] || m.signalsAreExclusive(&m.Signals[owner], &m.Signals[i]) {
+					continue
+				}
+
+				overlaps[[2]int{owner, i}] = true
+				errs = append(errs, errorAtLine(m.lineNumber, "signals '%s' and '%s' cannot overlap", m.Signals[owner].Name, m.Signals[i].Name))
+			}
+			bitOwners[p] = append(bitOwners[p], i)
+		}
+	}
+
+	return errs
 }
 
 func NewMessageFromLines(lines []string, startLineNumber int) (*Message, error) {
-	message := &Message{
-		lineNumber: startLineNumber,
+	message, errs := parseMessage(lines, startLineNumber)
+	if len(errs) > 0 {
+		return nil, errs[0]
 	}
 
-	for i := range message.signalsBitPositions {
-		message.signalsBitPositions[i] = -1
+	return message, nil
+}
+
+// parseMessage parses a BO_ line and its SG_ lines, leaving out the signals
+// that fail with their errors. The message is nil if the BO_ line fails.
+func parseMessage(lines []string, startLineNumber int) (*Message, []error) {
+	message := &Message{
+		lineNumber: startLineNumber,
 	}
 
 	if !strings.HasPrefix(lines[0], "BO_") {
-		return nil, errorAtLine(message.lineNumber, "message line does not start with 'BO_'")
+		return nil, []error{errorAtLine(message.lineNumber, "message line does not start with 'BO_'")}
 	}
 
 	messageDefinition := lines[0]
 	if err := message.parseDefinition(messageDefinition); err != nil {
-		return nil, err
+		return nil, []error{err}
 	}
 
 	if len(lines) == 1 {
 		return message, nil
 	}
 
+	var errs []error
 	for i, line := range lines[1:] {
+		totalLength := message.signalsTotalLength
 		signal, err := NewSignalFromLine(message, line, startLineNumber+1+i)
 		if err != nil {
-			return nil, err
+			// The signal may have been counted before failing
+			message.signalsTotalLength = totalLength
+			errs = append(errs, err)
+			continue
 		}
 
 		message.Signals = append(message.Signals, *signal)
 	}
 
-	return message, nil
+	message.resolveMultiplexors()
+
+	return message, errs
+}
+
+// IsFD reports whether the message needs a CAN FD frame, being longer
+// than 8 bytes.
+func (m *Message) IsFD() bool {
+	return m.DLC > 8
+}
+
+// IsReceivedBy reports whether any signal of the message is received by
+// node.
+func (m *Message) IsReceivedBy(node Node) bool {
+	for _, s := range m.Signals {
+		if slices.Contains(s.Receivers, node) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CycleTime returns the GenMsgCycleTime attribute of the message, in
+// milliseconds, or 0 if the message is not sent periodically.
+func (m *Message) CycleTime() uint32 {
+	cycleTime, ok := m.Attributes.Int("GenMsgCycleTime")
+	if !ok || cycleTime <= 0 || cycleTime > math.MaxUint32 {
+		return 0
+	}
+
+	return uint32(cycleTime)
+}
+
+// resolveMultiplexors links every multiplexed signal to the message's
+// multiplexor. Messages using extended multiplexing have more than one
+// multiplexor and rely on SG_MUL_VAL_ instead.
+func (m *Message) resolveMultiplexors() {
+	var multiplexors []string
+	for _, s := range m.Signals {
+		if s.IsMultiplexor && !s.IsMultiplexed() {
+			multiplexors = append(multiplexors, s.Name)
+		}
+	}
+	if len(multiplexors) != 1 {
+		return
+	}
+
+	for i := range m.Signals {
+		if m.Signals[i].IsMultiplexed() && m.Signals[i].Multiplexor == "" {
+			m.Signals[i].Multiplexor = multiplexors[0]
+		}
+	}
+}
+
+func (m *Message) Signal(name string) *Signal {
+	for i := range m.Signals {
+		if m.Signals[i].Name == name {
+			return &m.Signals[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *Message) validateMultiplexing(s *Signal) error {
+	if !s.IsMultiplexed() {
+		return nil
+	}
+
+	visited := map[string]bool{s.Name: true}
+	for current := s; current.IsMultiplexed(); {
+		if current.Multiplexor == "" {
+			return errorAtLine(m.lineNumber, "multiplexed signal '%s' has no multiplexor", current.Name)
+		}
+
+		multiplexor := m.Signal(current.Multiplexor)
+		if multiplexor == nil || !multiplexor.IsMultiplexor {
+			return errorAtLine(m.lineNumber, "signal '%s' is multiplexed by '%s', which is not a multiplexor of the message", current.Name, current.Multiplexor)
+		}
+
+		if visited[multiplexor.Name] {
+			return errorAtLine(m.lineNumber, "signal '%s' has a multiplexing cycle", s.Name)
+		}
+		visited[multiplexor.Name] = true
+
+		current = multiplexor
+	}
+
+	return nil
+}
+
+// multiplexConditions returns, for every multiplexor a signal depends on
+// (directly or through extended multiplexing), the values it must have
+// for the signal to be present.
+func (m *Message) multiplexConditions(s *Signal) map[string][]MultiplexRange {
+	conditions := make(map[string][]MultiplexRange)
+
+	for current := s; current != nil && current.IsMultiplexed(); current = m.Signal(current.Multiplexor) {
+		if _, ok := conditions[current.Multiplexor]; ok {
+			break
+		}
+		conditions[current.Multiplexor] = current.MultiplexValues
+	}
+
+	return conditions
+}
+
+// signalsAreExclusive reports whether two signals can never be present in
+// the same frame, because they need disjoint values of some multiplexor.
+func (m *Message) signalsAreExclusive(s1, s2 *Signal) bool {
+	conditions1 := m.multiplexConditions(s1)
+	conditions2 := m.multiplexConditions(s2)
+
+	for multiplexor, ranges1 := range conditions1 {
+		ranges2, ok := conditions2[multiplexor]
+		if !ok {
+			continue
+		}
+
+		if !rangesIntersect(ranges1, ranges2) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func rangesIntersect(ranges1, ranges2 []MultiplexRange) bool {
+	for _, r1 := range ranges1 {
+		for _, r2 := range ranges2 {
+			if r1.Min <= r2.Max && r2.Min <= r1.Max {
+				return true
+			}
+		}
+	}
+
+	return false
 }
 
 func (m *Message) parseDefinition(line string) error {
@@ -126,24 +332,29 @@ BO_ <MessageID> <MessageName>: <DLC> <TransmitterNode>`)
 }
 
 func (m *Message) parseID(messageIDStr string) error {
-	if !strings.HasPrefix(messageIDStr, "0x") {
-		messageID, err := strconv.Atoi(messageIDStr)
-		if err != nil {
-			return errorAtLine(m.lineNumber, "message ID must be a base 10 or hexadecimal integer")
-		}
-
-		m.ID = uint32(messageID)
-
-		return nil
+	base := 10
+	if strings.HasPrefix(messageIDStr, "0x") {
+		base = 16
+		messageIDStr = strings.TrimPrefix(messageIDStr, "0x")
 	}
 
-	messageIDHex := strings.TrimPrefix(messageIDStr, "0x")
-	messageID, err := strconv.ParseUint(messageIDHex, 16, 32)
+	messageID, err := strconv.ParseUint(messageIDStr, base, 32)
 	if err != nil {
 		return errorAtLine(m.lineNumber, "message ID must be a base 10 or hexadecimal integer")
 	}
 
-	m.ID = uint32(messageID)
+	m.IsExtended = messageID&extendedIDFlag != 0
+	m.ID = uint32(messageID &^ extendedIDFlag)
 
 	return nil
 }
+
+// dbcID returns the ID of the message as written in DBC files, with bit 31
+// set for extended frames.
+func (m *Message) dbcID() uint32 {
+	if m.IsExtended {
+		return m.ID | extendedIDFlag
+	}
+
+	return m.ID
+}
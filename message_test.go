@@ -28,12 +28,12 @@ SG_ OilTemperature : 16|8@1- (1,-40) [-40|150] "ºC" DriverGateway,EngineGateway
 		a.Len(message.Signals, 2)
 		a.Equal("EngineSpeed", message.Signals[0].Name)
 		a.Equal("OilTemperature", message.Signals[1].Name)
-		a.Equal(uint8(0), message.Signals[0].StartBit)
-		a.Equal(uint8(16), message.Signals[1].StartBit)
+		a.Equal(uint16(0), message.Signals[0].StartBit)
+		a.Equal(uint16(16), message.Signals[1].StartBit)
 		a.Equal(uint8(16), message.Signals[0].Length)
 		a.Equal(uint8(8), message.Signals[1].Length)
-		a.Equal(BigEndian, message.Signals[0].Endianness)
-		a.Equal(BigEndian, message.Signals[1].Endianness)
+		a.Equal(LittleEndian, message.Signals[0].Endianness)
+		a.Equal(LittleEndian, message.Signals[1].Endianness)
 		a.False(message.Signals[0].Signed)
 		a.True(message.Signals[1].Signed)
 		a.Equal(float32(0.1), message.Signals[0].Factor)
@@ -72,6 +72,38 @@ func TestParseMessageID(t *testing.T) {
 		a.Equal(uint32(123), message.ID)
 	})
 
+	t.Run("should parse extended message ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{}
+		err := message.parseID("2147483904")
+		a.Nil(err)
+		a.True(message.IsExtended)
+		a.Equal(uint32(0x100), message.ID)
+		a.Equal(uint32(0x80000100), message.dbcID())
+	})
+
+	t.Run("should parse extended hexadecimal message ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{}
+		err := message.parseID("0x98FEF100")
+		a.Nil(err)
+		a.True(message.IsExtended)
+		a.Equal(uint32(0x18FEF100), message.ID)
+	})
+
+	t.Run("should parse standard message ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{}
+		err := message.parseID("256")
+		a.Nil(err)
+		a.False(message.IsExtended)
+		a.Equal(uint32(0x100), message.ID)
+		a.Equal(uint32(0x100), message.dbcID())
+	})
+
 	t.Run("should return error for invalid decimal ID", func(t *testing.T) {
 		a := assert.New(t)
 
@@ -145,6 +177,98 @@ func TestMessageValidate(t *testing.T) {
 		a.Error(err)
 	})
 
+	t.Run("should validate adjacent big endian signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			ID:          123,
+			Name:        "InverterStatus",
+			DLC:         4,
+			Transmitter: "Inverter",
+			Signals: []Signal{
+				{
+					Name:       "Torque",
+					StartBit:   7,
+					Length:     16,
+					Endianness: BigEndian,
+					Factor:     1,
+				},
+				{
+					Name:       "Current",
+					StartBit:   23,
+					Length:     12,
+					Endianness: BigEndian,
+					Factor:     1,
+				},
+				{
+					Name:       "Status",
+					StartBit:   24,
+					Length:     4,
+					Endianness: LittleEndian,
+					Factor:     1,
+				},
+			},
+		}
+
+		err := message.Validate()
+		a.Nil(err)
+	})
+
+	t.Run("should return error for overlapping big endian signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			ID:          123,
+			Name:        "InverterStatus",
+			DLC:         2,
+			Transmitter: "Inverter",
+			Signals: []Signal{
+				{
+					Name:       "Torque",
+					StartBit:   7,
+					Length:     12,
+					Endianness: BigEndian,
+					Factor:     1,
+				},
+				{
+					Name:       "Status",
+					StartBit:   12,
+					Length:     4,
+					Endianness: LittleEndian,
+					Factor:     1,
+				},
+			},
+		}
+
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "signals 'Torque' and 'Status' cannot overlap")
+	})
+
+	t.Run("should return error when big endian signal exceeds the payload", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			ID:          123,
+			Name:        "InverterStatus",
+			DLC:         1,
+			Transmitter: "Inverter",
+			Signals: []Signal{
+				{
+					Name:       "Torque",
+					StartBit:   3,
+					Length:     8,
+					Endianness: BigEndian,
+					Factor:     1,
+				},
+			},
+		}
+
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "does not fit in the message payload")
+	})
+
 	t.Run("should return error for invalid signal", func(t *testing.T) {
 		a := assert.New(t)
 
@@ -169,3 +293,232 @@ func TestMessageValidate(t *testing.T) {
 		a.Contains(err.Error(), "signal factor cannot be zero")
 	})
 }
+
+func TestMessageMultiplexing(t *testing.T) {
+	t.Run("should link multiplexed signals to the multiplexor", func(t *testing.T) {
+		a := assert.New(t)
+
+		messageStr := `BO_ 200 CellVoltages: 8 BMS
+	SG_ CellIndex M : 0|8@1+ (1,0) [0|255] "" VCU
+	SG_ Cell0 m0 : 8|16@1+ (0.001,0) [0|5] "V" VCU
+	SG_ Cell1 m1 : 8|16@1+ (0.001,0) [0|5] "V" VCU
+	SG_ PackVoltage : 24|16@1+ (0.01,0) [0|600] "V" VCU`
+		message, err := NewMessageFromLines(strings.Split(messageStr, "\n"), 0)
+		a.Nil(err)
+		a.True(message.Signals[0].IsMultiplexor)
+		a.Equal("CellIndex", message.Signals[1].Multiplexor)
+		a.Equal("CellIndex", message.Signals[2].Multiplexor)
+		a.Equal("", message.Signals[3].Multiplexor)
+
+		err = message.Validate()
+		a.Nil(err)
+	})
+
+	t.Run("should return error when multiplexed signal overlaps a plain one", func(t *testing.T) {
+		a := assert.New(t)
+
+		messageStr := `BO_ 200 CellVoltages: 8 BMS
+	SG_ CellIndex M : 0|8@1+ (1,0) [0|255] "" VCU
+	SG_ Cell0 m0 : 8|16@1+ (0.001,0) [0|5] "V" VCU
+	SG_ PackVoltage : 16|16@1+ (0.01,0) [0|600] "V" VCU`
+		message, err := NewMessageFromLines(strings.Split(messageStr, "\n"), 0)
+		a.Nil(err)
+
+		err = message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "signals 'Cell0' and 'PackVoltage' cannot overlap")
+	})
+
+	t.Run("should return error when multiplexed signals share a multiplexor value", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			Name: "CellVoltages",
+			DLC:  8,
+			Signals: []Signal{
+				{Name: "CellIndex", Length: 8, Factor: 1, IsMultiplexor: true},
+				{Name: "Cell0", StartBit: 8, Length: 16, Factor: 1, Multiplexor: "CellIndex", MultiplexValues: []MultiplexRange{{Min: 0, Max: 2}}},
+				{Name: "Cell1", StartBit: 8, Length: 16, Factor: 1, Multiplexor: "CellIndex", MultiplexValues: []MultiplexRange{{Min: 2, Max: 4}}},
+			},
+		}
+
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "signals 'Cell0' and 'Cell1' cannot overlap")
+	})
+
+	t.Run("should allow overlaps across extended multiplexing groups", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			Name: "Diagnostics",
+			DLC:  8,
+			Signals: []Signal{
+				{Name: "Service", Length: 8, Factor: 1, IsMultiplexor: true},
+				{Name: "Subservice", StartBit: 8, Length: 8, Factor: 1, IsMultiplexor: true, Multiplexor: "Service", MultiplexValues: []MultiplexRange{{Min: 1, Max: 1}}},
+				{Name: "Counter", StartBit: 16, Length: 16, Factor: 1, Multiplexor: "Subservice", MultiplexValues: []MultiplexRange{{Min: 1, Max: 1}}},
+				{Name: "Status", StartBit: 16, Length: 8, Factor: 1, Multiplexor: "Subservice", MultiplexValues: []MultiplexRange{{Min: 2, Max: 3}}},
+				{Name: "RawData", StartBit: 8, Length: 32, Factor: 1, Multiplexor: "Service", MultiplexValues: []MultiplexRange{{Min: 2, Max: 2}}},
+			},
+		}
+
+		err := message.Validate()
+		a.Nil(err)
+	})
+
+	t.Run("should return error for multiplexed signal without multiplexor", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			Name: "CellVoltages",
+			DLC:  8,
+			Signals: []Signal{
+				{Name: "Cell0", StartBit: 8, Length: 16, Factor: 1, MultiplexValues: []MultiplexRange{{Min: 0, Max: 0}}},
+			},
+		}
+
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "multiplexed signal 'Cell0' has no multiplexor")
+	})
+
+	t.Run("should return error when multiplexor is not a multiplexor signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			Name: "CellVoltages",
+			DLC:  8,
+			Signals: []Signal{
+				{Name: "CellIndex", Length: 8, Factor: 1},
+				{Name: "Cell0", StartBit: 8, Length: 16, Factor: 1, Multiplexor: "CellIndex", MultiplexValues: []MultiplexRange{{Min: 0, Max: 0}}},
+			},
+		}
+
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "which is not a multiplexor of the message")
+	})
+}
+
+func TestMessageCANFD(t *testing.T) {
+	t.Run("should validate signals at the end of a 64 bytes payload", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			Name: "Telemetry",
+			DLC:  64,
+			Signals: []Signal{
+				{Name: "First", StartBit: 0, Length: 16, Factor: 1},
+				{Name: "Last", StartBit: 496, Length: 16, Factor: 1},
+				{Name: "MotorolaLast", StartBit: 487, Length: 16, Endianness: BigEndian, Factor: 1},
+			},
+		}
+
+		err := message.Validate()
+		a.Nil(err)
+		a.True(message.IsFD())
+	})
+
+	t.Run("should accept every CAN FD length", func(t *testing.T) {
+		a := assert.New(t)
+
+		for _, dlc := range []uint8{12, 16, 20, 24, 32, 48, 64} {
+			message := &Message{Name: "Telemetry", DLC: dlc}
+			a.Nil(message.Validate(), "DLC %d", dlc)
+		}
+	})
+
+	t.Run("should return error for lengths CAN FD cannot carry", func(t *testing.T) {
+		a := assert.New(t)
+
+		for _, dlc := range []uint8{9, 15, 33, 65} {
+			message := &Message{Name: "Telemetry", DLC: dlc}
+			err := message.Validate()
+			a.Error(err, "DLC %d", dlc)
+		}
+	})
+
+	t.Run("should return error when signal exceeds the FD payload", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{
+			Name: "Telemetry",
+			DLC:  12,
+			Signals: []Signal{
+				{Name: "Last", StartBit: 90, Length: 8, Factor: 1},
+			},
+		}
+
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "signal 'Last' does not fit in the message payload")
+	})
+
+	t.Run("should not be FD for classic lengths", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{Name: "Status", DLC: 8}
+		a.False(message.IsFD())
+	})
+}
+
+func TestMessageValidateID(t *testing.T) {
+	t.Run("should return error for standard ID above 0x7FF", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{Name: "Status", ID: 0x800, DLC: 8}
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "standard message ID must be a number between 0x0 and 0x7ff")
+	})
+
+	t.Run("should validate extended ID above 0x7FF", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{Name: "Status", ID: 0x18FEF100, IsExtended: true, DLC: 8}
+		a.Nil(message.Validate())
+	})
+
+	t.Run("should return error for extended ID above 29 bits", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{Name: "Status", ID: 0x20000000, IsExtended: true, DLC: 8}
+		err := message.Validate()
+		a.Error(err)
+		a.Contains(err.Error(), "extended message ID")
+	})
+}
+
+func TestMessageIsReceivedBy(t *testing.T) {
+	t.Run("should tell whether any signal is received by the node", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := Message{
+			Signals: []Signal{
+				{Name: "Speed", Receivers: []Node{"Dashboard"}},
+				{Name: "Torque", Receivers: []Node{"Logger", "VCU"}},
+			},
+		}
+
+		a.True(message.IsReceivedBy("Dashboard"))
+		a.True(message.IsReceivedBy("VCU"))
+		a.False(message.IsReceivedBy("Engine"))
+	})
+}
+
+func TestMessageCycleTime(t *testing.T) {
+	t.Run("should return the GenMsgCycleTime attribute", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := Message{Attributes: Attributes{"GenMsgCycleTime": int64(100)}}
+		a.Equal(uint32(100), message.CycleTime())
+	})
+
+	t.Run("should return 0 for messages that are not periodic", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal(uint32(0), (&Message{}).CycleTime())
+		a.Equal(uint32(0), (&Message{Attributes: Attributes{"GenMsgCycleTime": int64(-1)}}).CycleTime())
+		a.Equal(uint32(0), (&Message{Attributes: Attributes{"GenMsgCycleTime": "fast"}}).CycleTime())
+	})
+}
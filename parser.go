@@ -3,15 +3,78 @@ package vera
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 )
 
+type signalMultiplexValues struct {
+	messageID   uint32
+	signalName  string
+	multiplexor string
+	values      []MultiplexRange
+	lineNumber  int
+}
+
+type objectComment struct {
+	// object is the keyword of what the comment refers to: BU_, BO_, SG_,
+	// or empty for the network.
+	object     string
+	node       Node
+	messageID  uint32
+	signalName string
+	text       string
+	lineNumber int
+}
+
+type signalValueType struct {
+	messageID  uint32
+	signalName string
+	valueType  ValueType
+	lineNumber int
+}
+
+type signalValueDescriptions struct {
+	messageID    uint32
+	signalName   string
+	descriptions map[int64]string
+	lineNumber   int
+}
+
+// ParseOptions tunes ParseWith.
+type ParseOptions struct {
+	// File is the name of the parsed file, reported in the diagnostics of
+	// parsing and of Config.Validate.
+	File string
+	// AllErrors makes parsing go on after errors, skipping the statements
+	// that fail, so that all of them are reported at once. Signals that
+	// fail are left out of their message, and messages whose BO_ line fails
+	// are skipped up to the next statement. The configuration parsed so far
+	// is returned along with the Diagnostics.
+	AllErrors bool
+	// Importer, if set, reads the file instead of the DBC parser.
+	Importer Importer
+}
+
+// Importer reads a network description other than a DBC file, such as
+// AUTOSAR ARXML or KCD, into a configuration. Signal multiplexors may be
+// left out when messages have a single one, as in SG_ lines.
+type Importer func(r io.Reader) (*Config, error)
+
+// Parse parses a DBC file, returning Diagnostics holding the first error.
 func Parse(r io.Reader) (*Config, error) {
+	return ParseWith(r, ParseOptions{})
+}
+
+func ParseWith(r io.Reader, opts ParseOptions) (*Config, error) {
+	if opts.Importer != nil {
+		return importWith(r, opts)
+	}
+
 	bytes, err := io.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	config := &Config{}
+	config := &Config{file: opts.File}
 
 	content := string(bytes)
 	content = replaceNewLineCharacters(content)
@@ -21,38 +84,257 @@ func Parse(r io.Reader) (*Config, error) {
 		return config, nil
 	}
 
+	p := &parser{opts: opts, lines: lines}
+
+	var comments []objectComment
+	var attributeDefinitions []AttributeDefinition
+	var attributeDefaults []attributeDefault
+	var attributes []objectAttribute
+	var valueDescriptions []signalValueDescriptions
+	var valueTypes []signalValueType
+	var multiplexValues []signalMultiplexValues
+
 	for i := 0; i < len(lines); i++ {
-		if strings.HasPrefix(lines[i], "BO_") {
+		if strings.HasPrefix(lines[i], "BU_:") || strings.HasPrefix(lines[i], "BU_ ") {
+			nodes, err := parseNodes(lines[i], i)
+			if err != nil {
+				if p.report(err, CodeSyntax, i) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			config.Nodes = append(config.Nodes, nodes...)
+		} else if strings.HasPrefix(lines[i], "BO_") {
 			j := i + 1
 			for ; j < len(lines); j++ {
 				line := strings.TrimFunc(lines[j], func(r rune) bool {
 					return r == ' ' || r == '\t'
 				})
-				if !strings.HasPrefix(line, "SG_") {
+				if !strings.HasPrefix(line, "SG_ ") && !strings.HasPrefix(line, "SG_\t") {
 					break
 				}
 			}
 
-			message, err := NewMessageFromLines(lines[i:j], i)
-			if err != nil {
-				return nil, err
+			message, errs := parseMessage(lines[i:j], i)
+			for _, err := range errs {
+				if p.report(err, CodeSyntax, i) {
+					return nil, p.diagnostics
+				}
 			}
 
-			config.Messages = append(config.Messages, *message)
+			if message != nil {
+				config.Messages = append(config.Messages, *message)
+			}
 			i = j - 1
 		} else if strings.HasPrefix(lines[i], "TP_") {
 			signalTopic, err := parseSignalTopic(lines[i])
 			if err != nil {
-				return nil, err
+				if p.report(err, CodeSyntax, i) {
+					return nil, p.diagnostics
+				}
+				continue
 			}
 
+			signalTopic.lineNumber = i
 			config.Topics = append(config.Topics, *signalTopic)
+		} else if strings.HasPrefix(lines[i], "CM_ ") {
+			statement, j := gatherStatement(lines, i)
+			comment, err := parseComment(statement, i)
+			i = j
+			if err != nil {
+				if p.report(err, CodeSyntax, j) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			if comment != nil {
+				comments = append(comments, *comment)
+			}
+		} else if strings.HasPrefix(lines[i], "BA_DEF_ ") {
+			statement, j := gatherStatement(lines, i)
+			definition, err := parseAttributeDefinition(statement, i)
+			i = j
+			if err != nil {
+				if p.report(err, CodeSyntax, j) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			if definition != nil {
+				attributeDefinitions = append(attributeDefinitions, *definition)
+			}
+		} else if strings.HasPrefix(lines[i], "BA_DEF_DEF_ ") {
+			statement, j := gatherStatement(lines, i)
+			attributeDefault, err := parseAttributeDefault(statement, i)
+			i = j
+			if err != nil {
+				if p.report(err, CodeSyntax, j) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			attributeDefaults = append(attributeDefaults, *attributeDefault)
+		} else if strings.HasPrefix(lines[i], "BA_ ") {
+			statement, j := gatherStatement(lines, i)
+			attribute, err := parseAttribute(statement, i)
+			i = j
+			if err != nil {
+				if p.report(err, CodeSyntax, j) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			if attribute != nil {
+				attributes = append(attributes, *attribute)
+			}
+		} else if strings.HasPrefix(lines[i], "VAL_ ") {
+			signalValueDescriptions, err := parseValueDescriptions(lines[i], i)
+			if err != nil {
+				if p.report(err, CodeSyntax, i) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			if signalValueDescriptions != nil {
+				valueDescriptions = append(valueDescriptions, *signalValueDescriptions)
+			}
+		} else if strings.HasPrefix(lines[i], "SIG_VALTYPE_ ") {
+			signalValueType, err := parseValueType(lines[i], i)
+			if err != nil {
+				if p.report(err, CodeSyntax, i) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			valueTypes = append(valueTypes, *signalValueType)
+		} else if strings.HasPrefix(lines[i], "SG_MUL_VAL_ ") {
+			signalMultiplexValues, err := parseMultiplexValues(lines[i], i)
+			if err != nil {
+				if p.report(err, CodeSyntax, i) {
+					return nil, p.diagnostics
+				}
+				continue
+			}
+
+			multiplexValues = append(multiplexValues, *signalMultiplexValues)
 		}
 	}
 
+	attachErrors := [][]error{
+		config.attachComments(comments),
+		config.attachAttributes(attributeDefinitions, attributeDefaults, attributes),
+		config.attachValueDescriptions(valueDescriptions),
+		config.attachValueTypes(valueTypes),
+		config.attachMultiplexValues(multiplexValues),
+	}
+	for _, errs := range attachErrors {
+		for _, err := range errs {
+			if p.report(err, CodeInvalidReference, -1) {
+				return nil, p.diagnostics
+			}
+		}
+	}
+
+	return config, p.diagnostics.Err()
+}
+
+// importWith reads a configuration with opts.Importer, completing it as
+// the DBC parser does. Imported configurations have no line numbers.
+func importWith(r io.Reader, opts ParseOptions) (*Config, error) {
+	config, err := opts.Importer(r)
+	if err != nil {
+		if opts.File != "" {
+			return nil, fmt.Errorf("%s: %w", opts.File, err)
+		}
+		return nil, err
+	}
+
+	config.file = opts.File
+	for i := range config.Messages {
+		m := &config.Messages[i]
+		m.lineNumber = -1
+		m.signalsTotalLength = 0
+		for j := range m.Signals {
+			m.Signals[j].lineNumber = -1
+			if !m.Signals[j].IsMultiplexed() {
+				m.signalsTotalLength += uint16(m.Signals[j].Length)
+			}
+		}
+		m.resolveMultiplexors()
+	}
+	for i := range config.Topics {
+		config.Topics[i].lineNumber = -1
+	}
+	for i := range config.AttributeDefinitions {
+		config.AttributeDefinitions[i].lineNumber = -1
+	}
+
 	return config, nil
 }
 
+// parser collects the diagnostics of ParseWith.
+type parser struct {
+	opts        ParseOptions
+	lines       []string
+	diagnostics Diagnostics
+}
+
+// report records err, with code unless it has its own, and reports whether
+// parsing must stop. Errors without a line get lineNumber, unless it is
+// negative, and the column of the statement.
+func (p *parser) report(err error, code string, lineNumber int) bool {
+	diagnostic := asDiagnostic(err)
+	if diagnostic.Line == 0 && lineNumber >= 0 {
+		diagnostic.Line = lineNumber + 1
+	}
+	if diagnostic.Column == 0 && diagnostic.Line > 0 && diagnostic.Line <= len(p.lines) {
+		line := p.lines[diagnostic.Line-1]
+		diagnostic.Column = len(line) - len(strings.TrimLeft(line, " \t")) + 1
+	}
+
+	p.diagnostics.add(&diagnostic, code, p.opts.File)
+
+	return !p.opts.AllErrors
+}
+
+// gatherStatement returns the statement starting at lines[i], which spans
+// multiple lines while a quoted string is open, and the index of its last
+// line.
+func gatherStatement(lines []string, i int) (string, int) {
+	statement := lines[i]
+	j := i
+	for hasOpenQuote(statement) && j+1 < len(lines) {
+		j++
+		statement += "\n" + lines[j]
+	}
+
+	return statement, j
+}
+
+func parseNodes(line string, lineNumber int) ([]Node, error) {
+	nodesStr, ok := strings.CutPrefix(strings.TrimSpace(line), "BU_")
+	nodesStr = strings.TrimSpace(nodesStr)
+	if !ok || !strings.HasPrefix(nodesStr, ":") {
+		return nil, errorAtLine(lineNumber, `node list has wrong structure: %s
+Should be:
+	BU_: <NodeName> ...`, line)
+	}
+
+	var nodes []Node
+	for _, name := range strings.Fields(strings.TrimPrefix(nodesStr, ":")) {
+		nodes = append(nodes, Node(name))
+	}
+
+	return nodes, nil
+}
+
 func parseSignalTopic(topicLine string) (*SignalTopic, error) {
 	lineParts := strings.Fields(topicLine)
 	if len(lineParts) != 3 {
@@ -74,3 +356,286 @@ Should be:
 
 	return signalTopic, nil
 }
+
+// parseComment parses a CM_ statement, returning nil for comments on
+// objects vera does not know, such as environment variables.
+func parseComment(statement string, lineNumber int) (*objectComment, error) {
+	statement = strings.TrimSpace(statement)
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+
+	lineParts := splitFields(statement)
+	if len(lineParts) < 2 || !isQuoted(lineParts[len(lineParts)-1]) {
+		return nil, errorAtLine(lineNumber, `comment has wrong structure: %s
+Should be one of:
+	CM_ "<Comment>";
+	CM_ BU_ <NodeName> "<Comment>";
+	CM_ BO_ <MessageID> "<Comment>";
+	CM_ SG_ <MessageID> <SignalName> "<Comment>";`, statement)
+	}
+
+	comment := &objectComment{
+		text:       unquote(lineParts[len(lineParts)-1]),
+		lineNumber: lineNumber,
+	}
+
+	if len(lineParts) == 2 {
+		return comment, nil
+	}
+
+	comment.object = lineParts[1]
+	switch {
+	case comment.object == "BU_" && len(lineParts) == 4:
+		comment.node = Node(lineParts[2])
+	case comment.object == "BO_" && len(lineParts) == 4,
+		comment.object == "SG_" && len(lineParts) == 5:
+		messageID, err := strconv.ParseUint(lineParts[2], 10, 32)
+		if err != nil {
+			return nil, errorAtLine(lineNumber, "comment has invalid message ID: %s", lineParts[2])
+		}
+		comment.messageID = uint32(messageID)
+
+		if comment.object == "SG_" {
+			comment.signalName = lineParts[3]
+		}
+	case comment.object == "BU_" || comment.object == "BO_" || comment.object == "SG_":
+		return nil, errorAtLine(lineNumber, "comment has wrong structure: %s", statement)
+	default:
+		return nil, nil
+	}
+
+	return comment, nil
+}
+
+func (c *Config) attachComments(comments []objectComment) []error {
+	var errs []error
+	for _, cm := range comments {
+		switch cm.object {
+		case "":
+			c.Comment = cm.text
+		case "BU_":
+			if c.NodeComments == nil {
+				c.NodeComments = make(map[Node]string)
+			}
+			c.NodeComments[cm.node] = cm.text
+		case "BO_":
+			message := c.findMessage(cm.messageID)
+			if message == nil {
+				errs = append(errs, errorAtLine(cm.lineNumber, "comment refers to unknown message %d", cm.messageID))
+				continue
+			}
+
+			message.Comment = cm.text
+		case "SG_":
+			signal := c.findSignal(cm.messageID, cm.signalName)
+			if signal == nil {
+				errs = append(errs, errorAtLine(cm.lineNumber, "comment refers to unknown signal '%s' in message %d", cm.signalName, cm.messageID))
+				continue
+			}
+
+			signal.Comment = cm.text
+		}
+	}
+
+	return errs
+}
+
+// parseValueDescriptions parses a VAL_ statement, returning nil for the
+// value tables of environment variables, which vera does not know.
+func parseValueDescriptions(line string, lineNumber int) (*signalValueDescriptions, error) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSpace(strings.TrimSuffix(line, ";"))
+
+	lineParts := splitFields(line)
+	// VAL_ <EnvVarName> <Value> "<Description>" ... has no signal name, so
+	// an even number of fields.
+	if len(lineParts)%2 == 0 {
+		if _, err := strconv.ParseUint(lineParts[1], 10, 32); err != nil {
+			return nil, nil
+		}
+	}
+
+	if len(lineParts) < 5 || len(lineParts)%2 != 1 {
+		return nil, errorAtLine(lineNumber, `value descriptions have wrong structure: %s
+Should be:
+	VAL_ <MessageID> <SignalName> <Value> "<Description>" ... ;`, line)
+	}
+
+	messageID, err := strconv.ParseUint(lineParts[1], 10, 32)
+	if err != nil {
+		return nil, errorAtLine(lineNumber, "value descriptions have invalid message ID: %s", lineParts[1])
+	}
+
+	signalValueDescriptions := &signalValueDescriptions{
+		messageID:    uint32(messageID),
+		signalName:   lineParts[2],
+		descriptions: make(map[int64]string),
+		lineNumber:   lineNumber,
+	}
+
+	for i := 3; i < len(lineParts); i += 2 {
+		value, err := strconv.ParseInt(lineParts[i], 10, 64)
+		if err != nil {
+			return nil, errorAtLine(lineNumber, "value descriptions have invalid value: %s", lineParts[i])
+		}
+
+		description := lineParts[i+1]
+		if !isQuoted(description) {
+			return nil, errorAtLine(lineNumber, "value descriptions have invalid description: %s", description)
+		}
+
+		signalValueDescriptions.descriptions[value] = unquote(description)
+	}
+
+	return signalValueDescriptions, nil
+}
+
+func (c *Config) attachValueDescriptions(valueDescriptions []signalValueDescriptions) []error {
+	var errs []error
+	for _, vd := range valueDescriptions {
+		signal := c.findSignal(vd.messageID, vd.signalName)
+		if signal == nil {
+			errs = append(errs, errorAtLine(vd.lineNumber, "value descriptions refer to unknown signal '%s' in message %d", vd.signalName, vd.messageID))
+			continue
+		}
+
+		signal.ValueDescriptions = vd.descriptions
+	}
+
+	return errs
+}
+
+func parseValueType(line string, lineNumber int) (*signalValueType, error) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSpace(strings.TrimSuffix(line, ";"))
+
+	lineParts := strings.Fields(strings.Replace(line, ":", " : ", 1))
+	if len(lineParts) != 5 || lineParts[3] != ":" {
+		return nil, errorAtLine(lineNumber, `value type has wrong structure: %s
+Should be:
+	SIG_VALTYPE_ <MessageID> <SignalName> : <0|1|2>;`, line)
+	}
+
+	messageID, err := strconv.ParseUint(lineParts[1], 10, 32)
+	if err != nil {
+		return nil, errorAtLine(lineNumber, "value type has invalid message ID: %s", lineParts[1])
+	}
+
+	valueType, err := strconv.ParseUint(lineParts[4], 10, 8)
+	if err != nil || ValueType(valueType) > DoubleValue {
+		return nil, errorAtLine(lineNumber, "value type must be 0 (integer), 1 (float) or 2 (double): %s", lineParts[4])
+	}
+
+	return &signalValueType{
+		messageID:  uint32(messageID),
+		signalName: lineParts[2],
+		valueType:  ValueType(valueType),
+		lineNumber: lineNumber,
+	}, nil
+}
+
+func (c *Config) attachValueTypes(valueTypes []signalValueType) []error {
+	var errs []error
+	for _, vt := range valueTypes {
+		signal := c.findSignal(vt.messageID, vt.signalName)
+		if signal == nil {
+			errs = append(errs, errorAtLine(vt.lineNumber, "value type refers to unknown signal '%s' in message %d", vt.signalName, vt.messageID))
+			continue
+		}
+
+		signal.ValueType = vt.valueType
+	}
+
+	return errs
+}
+
+func (c *Config) findMessage(messageID uint32) *Message {
+	for i := range c.Messages {
+		if c.Messages[i].dbcID() == messageID {
+			return &c.Messages[i]
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) findSignal(messageID uint32, signalName string) *Signal {
+	for i := range c.Messages {
+		if c.Messages[i].dbcID() != messageID {
+			continue
+		}
+
+		if signal := c.Messages[i].Signal(signalName); signal != nil {
+			return signal
+		}
+	}
+
+	return nil
+}
+
+func parseMultiplexValues(line string, lineNumber int) (*signalMultiplexValues, error) {
+	line = strings.TrimSpace(line)
+	line = strings.TrimSpace(strings.TrimSuffix(line, ";"))
+
+	lineParts := strings.Fields(line)
+	if len(lineParts) < 5 {
+		return nil, errorAtLine(lineNumber, `multiplex values have wrong structure: %s
+Should be:
+	SG_MUL_VAL_ <MessageID> <SignalName> <MultiplexorName> <Min>-<Max>, ... ;`, line)
+	}
+
+	messageID, err := strconv.ParseUint(lineParts[1], 10, 32)
+	if err != nil {
+		return nil, errorAtLine(lineNumber, "multiplex values have invalid message ID: %s", lineParts[1])
+	}
+
+	signalMultiplexValues := &signalMultiplexValues{
+		messageID:   uint32(messageID),
+		signalName:  lineParts[2],
+		multiplexor: lineParts[3],
+		lineNumber:  lineNumber,
+	}
+
+	for rangeStr := range strings.SplitSeq(strings.Join(lineParts[4:], ""), ",") {
+		bounds := strings.Split(rangeStr, "-")
+		if len(bounds) != 2 {
+			return nil, errorAtLine(lineNumber, "multiplex values have invalid range: %s", rangeStr)
+		}
+
+		rangeMin, err := strconv.ParseUint(bounds[0], 10, 64)
+		if err != nil {
+			return nil, errorAtLine(lineNumber, "multiplex values have invalid range: %s", rangeStr)
+		}
+		rangeMax, err := strconv.ParseUint(bounds[1], 10, 64)
+		if err != nil {
+			return nil, errorAtLine(lineNumber, "multiplex values have invalid range: %s", rangeStr)
+		}
+
+		signalMultiplexValues.values = append(signalMultiplexValues.values, MultiplexRange{
+			Min: rangeMin,
+			Max: rangeMax,
+		})
+	}
+
+	return signalMultiplexValues, nil
+}
+
+func (c *Config) attachMultiplexValues(multiplexValues []signalMultiplexValues) []error {
+	var errs []error
+	for _, mv := range multiplexValues {
+		signal := c.findSignal(mv.messageID, mv.signalName)
+		if signal == nil {
+			errs = append(errs, errorAtLine(mv.lineNumber, "multiplex values refer to unknown signal '%s' in message %d", mv.signalName, mv.messageID))
+			continue
+		}
+
+		if !signal.IsMultiplexed() {
+			errs = append(errs, errorAtLine(mv.lineNumber, "multiplex values refer to signal '%s', which is not multiplexed", mv.signalName))
+			continue
+		}
+
+		signal.Multiplexor = mv.multiplexor
+		signal.MultiplexValues = mv.values
+	}
+
+	return errs
+}
@@ -1,6 +1,8 @@
 package vera
 
 import (
+	"errors"
+	"io"
 	"strings"
 	"testing"
 
@@ -103,3 +105,460 @@ TP_ Speed vehicle/engine/speed`
 		a.Len(config.Topics, 1)
 	})
 }
+
+func TestParse_WithValueDescriptions(t *testing.T) {
+	t.Run("should attach value descriptions to signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `BO_ 123 Transmission: 8 Gearbox
+	SG_ Gear : 0|4@1+ (1,0) [0|15] "" Gateway
+	SG_ Fault : 4|4@1- (1,0) [-8|7] "" Gateway
+VAL_ 123 Gear 0 "Neutral" 1 "First gear" 15 "Not available" ;
+VAL_ 123 Fault -1 "Overheat" 0 "None";`
+		reader := strings.NewReader(configStr)
+
+		config, err := Parse(reader)
+		a.Nil(err)
+		a.NotNil(config)
+		a.Equal(map[int64]string{
+			0:  "Neutral",
+			1:  "First gear",
+			15: "Not available",
+		}, config.Messages[0].Signals[0].ValueDescriptions)
+		a.Equal(map[int64]string{
+			-1: "Overheat",
+			0:  "None",
+		}, config.Messages[0].Signals[1].ValueDescriptions)
+	})
+
+	t.Run("should not treat VAL_TABLE_ as value descriptions", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `VAL_TABLE_ Gears 1 "First" 0 "Neutral" ;
+BO_ 123 Transmission: 8 Gearbox
+	SG_ Gear : 0|4@1+ (1,0) [0|15] "" Gateway`
+		reader := strings.NewReader(configStr)
+
+		config, err := Parse(reader)
+		a.Nil(err)
+		a.Nil(config.Messages[0].Signals[0].ValueDescriptions)
+	})
+
+	t.Run("should skip value descriptions of environment variables", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `BO_ 123 Transmission: 8 Gearbox
+	SG_ Gear : 0|4@1+ (1,0) [0|15] "" Gateway
+VAL_ EnvVar 0 "Off" 1 "On" ;
+VAL_ 123 Gear 0 "Neutral" ;`
+		reader := strings.NewReader(configStr)
+
+		config, err := Parse(reader)
+		a.Nil(err)
+		a.Equal(map[int64]string{0: "Neutral"}, config.Messages[0].Signals[0].ValueDescriptions)
+	})
+
+	t.Run("should return error for unknown signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `BO_ 123 Transmission: 8 Gearbox
+	SG_ Gear : 0|4@1+ (1,0) [0|15] "" Gateway
+VAL_ 124 Gear 0 "Neutral" ;`
+		reader := strings.NewReader(configStr)
+
+		config, err := Parse(reader)
+		a.Error(err)
+		a.Nil(config)
+		a.Contains(err.Error(), "line 2: value descriptions refer to unknown signal 'Gear'")
+	})
+}
+
+func TestParseValueDescriptions(t *testing.T) {
+	t.Run("should return error for missing description", func(t *testing.T) {
+		a := assert.New(t)
+
+		vd, err := parseValueDescriptions(`VAL_ 123 Gear 0 "Neutral" 1 ;`, 0)
+		a.Error(err)
+		a.Nil(vd)
+		a.Contains(err.Error(), "value descriptions have wrong structure")
+	})
+
+	t.Run("should return error for invalid value", func(t *testing.T) {
+		a := assert.New(t)
+
+		vd, err := parseValueDescriptions(`VAL_ 123 Gear x "Neutral" ;`, 0)
+		a.Error(err)
+		a.Nil(vd)
+		a.Contains(err.Error(), "invalid value")
+	})
+
+	t.Run("should return error for unquoted description", func(t *testing.T) {
+		a := assert.New(t)
+
+		vd, err := parseValueDescriptions(`VAL_ 123 Gear 0 Neutral ;`, 0)
+		a.Error(err)
+		a.Nil(vd)
+		a.Contains(err.Error(), "invalid description")
+	})
+
+	t.Run("should return error for invalid message ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		vd, err := parseValueDescriptions(`VAL_ abc Gear 0 "Neutral" ;`, 0)
+		a.Error(err)
+		a.Nil(vd)
+		a.Contains(err.Error(), "invalid message ID")
+	})
+}
+
+func TestParse_WithMultiplexValues(t *testing.T) {
+	t.Run("should apply extended multiplex ranges", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `BO_ 200 Diagnostics: 8 BMS
+	SG_ Service M : 0|8@1+ (1,0) [0|255] "" VCU
+	SG_ Subservice m1M : 8|8@1+ (1,0) [0|255] "" VCU
+	SG_ Counter m1 : 16|16@1+ (1,0) [0|65535] "" VCU
+	SG_ RawData m2 : 8|32@1+ (1,0) [0|4294967295] "" VCU
+SG_MUL_VAL_ 200 Subservice Service 1-1;
+SG_MUL_VAL_ 200 Counter Subservice 1-1, 4-6 ;
+SG_MUL_VAL_ 200 RawData Service 2-3;`
+		reader := strings.NewReader(configStr)
+
+		config, err := Parse(reader)
+		a.Nil(err)
+		a.Len(config.Messages, 1)
+
+		signals := config.Messages[0].Signals
+		a.Len(signals, 4)
+		a.Equal("Service", signals[1].Multiplexor)
+		a.Equal("Subservice", signals[2].Multiplexor)
+		a.Equal([]MultiplexRange{{Min: 1, Max: 1}, {Min: 4, Max: 6}}, signals[2].MultiplexValues)
+		a.Equal("Service", signals[3].Multiplexor)
+		a.Equal([]MultiplexRange{{Min: 2, Max: 3}}, signals[3].MultiplexValues)
+
+		err = config.Validate()
+		a.Nil(err)
+	})
+
+	t.Run("should return error for unknown signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `BO_ 200 Diagnostics: 8 BMS
+	SG_ Service M : 0|8@1+ (1,0) [0|255] "" VCU
+SG_MUL_VAL_ 200 Counter Service 1-1;`
+		reader := strings.NewReader(configStr)
+
+		config, err := Parse(reader)
+		a.Error(err)
+		a.Nil(config)
+		a.Contains(err.Error(), "line 2: multiplex values refer to unknown signal 'Counter'")
+	})
+}
+
+func TestParseMultiplexValues(t *testing.T) {
+	t.Run("should return error for invalid range", func(t *testing.T) {
+		a := assert.New(t)
+
+		mv, err := parseMultiplexValues("SG_MUL_VAL_ 200 Counter Service 1-x;", 0)
+		a.Error(err)
+		a.Nil(mv)
+		a.Contains(err.Error(), "invalid range")
+	})
+
+	t.Run("should return error for wrong structure", func(t *testing.T) {
+		a := assert.New(t)
+
+		mv, err := parseMultiplexValues("SG_MUL_VAL_ 200 Counter;", 0)
+		a.Error(err)
+		a.Nil(mv)
+		a.Contains(err.Error(), "wrong structure")
+	})
+}
+
+func TestParse_WithExtendedIDs(t *testing.T) {
+	t.Run("should tell apart standard and extended messages with the same ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `BO_ 256 Standard: 8 Engine
+	SG_ Gear : 0|4@1+ (1,0) [0|15] "" Gateway
+BO_ 2147483904 Extended: 8 Engine
+	SG_ Gear : 0|4@1+ (1,0) [0|15] "" Gateway
+VAL_ 2147483904 Gear 1 "First" ;`
+		reader := strings.NewReader(configStr)
+
+		config, err := Parse(reader)
+		a.Nil(err)
+		a.Len(config.Messages, 2)
+		a.Equal(uint32(0x100), config.Messages[0].ID)
+		a.False(config.Messages[0].IsExtended)
+		a.Equal(uint32(0x100), config.Messages[1].ID)
+		a.True(config.Messages[1].IsExtended)
+
+		a.Nil(config.Messages[0].Signals[0].ValueDescriptions)
+		a.Equal(map[int64]string{1: "First"}, config.Messages[1].Signals[0].ValueDescriptions)
+	})
+}
+
+func TestParse_WithNodes(t *testing.T) {
+	t.Run("should parse the BU_ node list", func(t *testing.T) {
+		a := assert.New(t)
+
+		configStr := `BU_: Engine DriverGateway
+BU_SG_REL_ SG_ Speed DriverGateway ;
+BO_ 123 EngineSpeed: 8 Engine
+	SG_ Speed : 0|2@1+ (0.1,0) [0|100] "km/h" DriverGateway`
+
+		config, err := Parse(strings.NewReader(configStr))
+		a.Nil(err)
+		a.Equal([]Node{"Engine", "DriverGateway"}, config.Nodes)
+	})
+
+	t.Run("should parse an empty node list", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader("BU_:"))
+		a.Nil(err)
+		a.Empty(config.Nodes)
+	})
+}
+
+func TestParseNodes(t *testing.T) {
+	t.Run("should parse nodes with a space before ':'", func(t *testing.T) {
+		a := assert.New(t)
+
+		nodes, err := parseNodes("BU_ : Engine\tBMS ", 0)
+		a.Nil(err)
+		a.Equal([]Node{"Engine", "BMS"}, nodes)
+	})
+
+	t.Run("should return error for missing ':'", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseNodes("BU_ Engine BMS", 3)
+		a.NotNil(err)
+		a.Contains(err.Error(), "line 3: node list has wrong structure")
+	})
+}
+
+func TestParse_WithComments(t *testing.T) {
+	configStr := `BU_: Engine VCU
+BO_ 123 EngineSpeed: 8 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+	SG_ Gear : 8|4@1+ (1,0) [0|15] "" VCU
+BO_ 2147483904 Extended: 1 Engine
+	SG_ Value : 0|8@1+ (1,0) [0|255] "" VCU
+
+CM_ "Powertrain network";
+CM_ BU_ Engine "Engine control unit";
+CM_ BO_ 123 "Sent every 10 ms.
+Speed is the wheel speed,
+not the engine one.";
+CM_ SG_ 123 Speed "Wheel \"speed\"";
+CM_ SG_ 2147483904 Value "Extended";
+CM_ EV_ SomeVariable "Ignored";
+TP_ Speed vehicle/speed`
+
+	t.Run("should attach comments to network, nodes, messages and signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(configStr))
+		a.Nil(err)
+		a.Equal("Powertrain network", config.Comment)
+		a.Equal(map[Node]string{"Engine": "Engine control unit"}, config.NodeComments)
+		a.Equal("Sent every 10 ms.\nSpeed is the wheel speed,\nnot the engine one.", config.Messages[0].Comment)
+		a.Equal(`Wheel "speed"`, config.Messages[0].Signals[0].Comment)
+		a.Empty(config.Messages[0].Signals[1].Comment)
+		a.Equal("Extended", config.Messages[1].Signals[0].Comment)
+	})
+
+	t.Run("should keep parsing after multi-line comments", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(configStr))
+		a.Nil(err)
+		a.Len(config.Topics, 1)
+	})
+
+	t.Run("should return error for comments on unknown objects", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`BO_ 123 EngineSpeed: 8 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+CM_ SG_ 123 Sped "Typo";`))
+		a.NotNil(err)
+		a.Equal("line 2: comment refers to unknown signal 'Sped' in message 123", err.Error())
+
+		_, err = Parse(strings.NewReader(`CM_ BO_ 124 "Unknown";`))
+		a.NotNil(err)
+		a.Equal("line 0: comment refers to unknown message 124", err.Error())
+	})
+}
+
+func TestParseComment(t *testing.T) {
+	t.Run("should return error for unquoted comment", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseComment("CM_ BO_ 123 Unquoted;", 4)
+		a.NotNil(err)
+		a.Contains(err.Error(), "line 4: comment has wrong structure")
+	})
+
+	t.Run("should return error for invalid message ID", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseComment(`CM_ BO_ abc "Comment";`, 4)
+		a.NotNil(err)
+		a.Equal("line 4: comment has invalid message ID: abc", err.Error())
+	})
+
+	t.Run("should return error for missing signal name", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseComment(`CM_ SG_ 123 "Comment";`, 4)
+		a.NotNil(err)
+		a.Contains(err.Error(), "line 4: comment has wrong structure")
+	})
+}
+
+func TestParse_WithValueTypes(t *testing.T) {
+	t.Run("should set the value type of signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BO_ 129 ImuData: 8 Imu
+	SG_ AccelX : 0|32@1- (1,0) [-100|100] "m/s2" VCU
+	SG_ Status : 32|8@1+ (1,0) [0|255] "" VCU
+BO_ 130 LoggerTime: 8 Logger
+	SG_ Uptime : 0|64@1- (1,0) [0|0] "s" VCU
+SIG_VALTYPE_ 129 AccelX : 1;
+SIG_VALTYPE_ 130 Uptime: 2;`))
+		a.Nil(err)
+		a.Equal(FloatValue, config.Messages[0].Signals[0].ValueType)
+		a.Equal(IntegerValue, config.Messages[0].Signals[1].ValueType)
+		a.Equal(DoubleValue, config.Messages[1].Signals[0].ValueType)
+		a.Nil(config.Validate())
+	})
+
+	t.Run("should return error for unknown signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader(`BO_ 129 ImuData: 8 Imu
+	SG_ AccelX : 0|32@1- (1,0) [-100|100] "m/s2" VCU
+SIG_VALTYPE_ 129 AccelY : 1;`))
+		a.Error(err)
+		a.Equal("line 2: value type refers to unknown signal 'AccelY' in message 129", err.Error())
+	})
+}
+
+func TestParseValueType(t *testing.T) {
+	t.Run("should return error for unknown value type", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseValueType("SIG_VALTYPE_ 129 AccelX : 3;", 4)
+		a.Error(err)
+		a.Equal("line 4: value type must be 0 (integer), 1 (float) or 2 (double): 3", err.Error())
+	})
+
+	t.Run("should return error for wrong structure", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := parseValueType("SIG_VALTYPE_ 129 AccelX 1;", 4)
+		a.Error(err)
+		a.Contains(err.Error(), "line 4: value type has wrong structure")
+	})
+}
+
+func TestParseWith_AllErrors(t *testing.T) {
+	configStr := `BO_ 123 EngineSpeed: 8 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" VCU
+	SG_ Bad : x|8@1+ (1,0) [0|255] "" VCU
+BO_ abc Broken: 8 Engine
+	SG_ Other : 0|8@1+ (1,0) [0|255] "" VCU
+BO_ 124 EngineStatus: 1 Engine
+	SG_ Status : 0|8@1+ (1,0) [0|255] "" VCU
+VAL_ 123 Speed 0 Off ;
+VAL_ 123 Bad 0 "Off" ;
+VAL_ 124 Status 0 "Off" ;`
+
+	t.Run("should stop at the first error by default", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := ParseWith(strings.NewReader(configStr), ParseOptions{File: "car.dbc"})
+		a.Nil(config)
+
+		var diagnostics Diagnostics
+		a.True(errors.As(err, &diagnostics))
+		a.Equal(Diagnostics{
+			{File: "car.dbc", Line: 3, Column: 2, Severity: SeverityError, Code: CodeSyntax, Message: "signal line has invalid start bit: x"},
+		}, diagnostics)
+	})
+
+	t.Run("should report every error and return the rest of the config", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := ParseWith(strings.NewReader(configStr), ParseOptions{File: "car.dbc", AllErrors: true})
+		a.NotNil(config)
+
+		var diagnostics Diagnostics
+		a.True(errors.As(err, &diagnostics))
+		a.Equal(Diagnostics{
+			{File: "car.dbc", Line: 3, Column: 2, Severity: SeverityError, Code: CodeSyntax, Message: "signal line has invalid start bit: x"},
+			{File: "car.dbc", Line: 4, Column: 1, Severity: SeverityError, Code: CodeSyntax, Message: "message ID must be a base 10 or hexadecimal integer"},
+			{File: "car.dbc", Line: 8, Column: 1, Severity: SeverityError, Code: CodeSyntax, Message: "value descriptions have invalid description: Off"},
+			{File: "car.dbc", Line: 9, Column: 1, Severity: SeverityError, Code: CodeInvalidReference, Message: "value descriptions refer to unknown signal 'Bad' in message 123"},
+		}, diagnostics)
+
+		a.Len(config.Messages, 2)
+		a.Len(config.Messages[0].Signals, 1)
+		a.Equal("EngineStatus", config.Messages[1].Name)
+		a.Equal(map[int64]string{0: "Off"}, config.Messages[1].Signals[0].ValueDescriptions)
+		a.Nil(config.Validate())
+	})
+
+	t.Run("should keep the line of errors without one", func(t *testing.T) {
+		a := assert.New(t)
+
+		_, err := Parse(strings.NewReader("BO_ 123 EngineSpeed: 8 Engine\nTP_ Speed"))
+		a.NotNil(err)
+		a.Contains(err.Error(), "line 1: signal topic has wrong structure")
+	})
+}
+
+func TestParseWith_Importer(t *testing.T) {
+	t.Run("should read the file with the importer", func(t *testing.T) {
+		a := assert.New(t)
+
+		importer := func(r io.Reader) (*Config, error) {
+			return &Config{
+				Nodes: []Node{"Engine"},
+				Messages: []Message{{
+					Name:        "EngineSpeed",
+					ID:          123,
+					DLC:         1,
+					Transmitter: "Logger",
+					Signals:     []Signal{{Name: "Speed", Length: 8, Factor: 1}},
+				}},
+			}, nil
+		}
+
+		config, err := ParseWith(strings.NewReader(""), ParseOptions{File: "car.kcd", Importer: importer})
+		a.Nil(err)
+
+		var diagnostics Diagnostics
+		a.True(errors.As(config.Validate(), &diagnostics))
+		a.Equal(Diagnostics{
+			{File: "car.kcd", Severity: SeverityError, Code: CodeUndeclaredNode, Message: "message transmitter 'Logger' is not declared in BU_"},
+		}, diagnostics)
+	})
+
+	t.Run("should return the errors of the importer", func(t *testing.T) {
+		a := assert.New(t)
+
+		importer := func(r io.Reader) (*Config, error) {
+			return nil, errors.New("kcd: unexpected EOF")
+		}
+
+		_, err := ParseWith(strings.NewReader(""), ParseOptions{File: "car.kcd", Importer: importer})
+		a.EqualError(err, "car.kcd: kcd: unexpected EOF")
+	})
+}
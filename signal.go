@@ -1,16 +1,18 @@
 package vera
 
 import (
+	"math"
 	"strconv"
 	"strings"
 )
 
 type Signal struct {
 	Name     string
-	StartBit uint8
+	StartBit uint16
 	Length   uint8
 	Endianness
 	Signed    bool
+	ValueType ValueType
 	Factor    float32
 	Offset    float32
 	Min       float32
@@ -18,13 +20,25 @@ type Signal struct {
 	Unit      string
 	Receivers []Node
 	Topic     string
+	// Comment is the CM_ SG_ comment of the signal.
+	Comment    string
+	Attributes Attributes
+	// ValueDescriptions maps raw values to their VAL_ descriptions.
+	ValueDescriptions map[int64]string
+	// IsMultiplexor is set for 'M' (and extended 'mNM') signals.
+	IsMultiplexor bool
+	// Multiplexor is the name of the signal selecting this one, and
+	// MultiplexValues the multiplexor values for which it is present.
+	// Both are empty for signals that are not multiplexed.
+	Multiplexor     string
+	MultiplexValues []MultiplexRange
 
 	lineNumber int
 }
 
 func (s *Signal) Validate() error {
-	if s.StartBit >= 64 {
-		return errorAtLine(s.lineNumber, "signal start bit must be a number between 0 and 63")
+	if s.StartBit >= maxDataLength*8 {
+		return errorAtLine(s.lineNumber, "signal start bit must be a number between 0 and %d", maxDataLength*8-1)
 	}
 	if s.Length > 64 {
 		return errorAtLine(s.lineNumber, "signal length must be a number between 1 and 64")
@@ -32,10 +46,99 @@ func (s *Signal) Validate() error {
 	if s.Factor == 0 {
 		return errorAtLine(s.lineNumber, "signal factor cannot be zero")
 	}
+	switch s.ValueType {
+	case FloatValue:
+		if s.Length != 32 {
+			return errorAtLine(s.lineNumber, "float signal length must be 32")
+		}
+	case DoubleValue:
+		if s.Length != 64 {
+			return errorAtLine(s.lineNumber, "double signal length must be 64")
+		}
+	}
+	if s.ValueType != IntegerValue && s.IsMultiplexor {
+		return errorAtLine(s.lineNumber, "multiplexor signal must be an integer")
+	}
+	for _, r := range s.MultiplexValues {
+		if r.Min > r.Max {
+			return errorAtLine(s.lineNumber, "signal multiplex range %d-%d is empty", r.Min, r.Max)
+		}
+	}
 
 	return nil
 }
 
+func (s *Signal) IsMultiplexed() bool {
+	return len(s.MultiplexValues) > 0
+}
+
+// StartValue returns the GenSigStartValue attribute of the signal, the raw
+// value it has until the application sets one, or 0 if it is not set.
+func (s *Signal) StartValue() int64 {
+	startValue, ok := s.Attributes.Float("GenSigStartValue")
+	if !ok {
+		return 0
+	}
+
+	return int64(math.Round(startValue))
+}
+
+// InvalidValue returns the GenSigInvalidValue attribute of the signal, the
+// raw value its transmitter sends when the signal is invalid, truncated to
+// the signal bits. ok is false if it is not set.
+func (s *Signal) InvalidValue() (raw uint64, ok bool) {
+	return s.rawAttribute("GenSigInvalidValue")
+}
+
+// NotAvailableValue returns the GenSigSNA attribute of the signal, the raw
+// value its transmitter sends when the signal is not available, truncated
+// to the signal bits. ok is false if it is not set.
+func (s *Signal) NotAvailableValue() (raw uint64, ok bool) {
+	return s.rawAttribute("GenSigSNA")
+}
+
+// rawAttribute returns an integer attribute as raw signal bits, so that
+// negative values of signed signals match their two's complement.
+func (s *Signal) rawAttribute(name string) (uint64, bool) {
+	value, ok := s.Attributes.Int(name)
+	if !ok {
+		return 0, false
+	}
+
+	raw := uint64(value)
+	if s.Length < 64 {
+		raw &= 1<<s.Length - 1
+	}
+
+	return raw, true
+}
+
+// bitPositions returns the payload bits occupied by the signal, numbered
+// as in the DBC: bit i is bit (i % 8) of byte (i / 8), LSB first.
+//
+// Intel signals grow from their start bit (the LSB) towards higher bits.
+// Motorola signals start at their MSB and follow the sawtooth numbering:
+// down to bit 0 of a byte, then on to bit 7 of the next one.
+func (s *Signal) bitPositions() []int {
+	positions := make([]int, 0, s.Length)
+
+	position := int(s.StartBit)
+	for range s.Length {
+		positions = append(positions, position)
+
+		switch {
+		case s.Endianness == LittleEndian:
+			position++
+		case position%8 == 0:
+			position += 15
+		default:
+			position--
+		}
+	}
+
+	return positions
+}
+
 func NewSignalFromLine(message *Message, line string, lineNumber int) (*Signal, error) {
 	signal := &Signal{
 		lineNumber: lineNumber,
@@ -56,6 +159,14 @@ func NewSignalFromLine(message *Message, line string, lineNumber int) (*Signal,
 
 	signal.Name = lineParts[1]
 
+	// The multiplex indicator is optional and sits between the name and ':'
+	if lineParts[2] != ":" {
+		if err := signal.parseMultiplexIndicator(lineParts[2]); err != nil {
+			return nil, err
+		}
+		lineParts = append(lineParts[:2], lineParts[3:]...)
+	}
+
 	if err := signal.parseBitInfo(message, lineParts[3]); err != nil {
 		return nil, err
 	}
@@ -82,16 +193,45 @@ func NewSignalFromLine(message *Message, line string, lineNumber int) (*Signal,
 func (s *Signal) checkLineStructure(lineParts []string) error {
 	if len(lineParts) < 7 {
 		return errorAtLine(s.lineNumber, `signal line is not well structured, must adhere to:
-SG_ <SignalName> : <StartBit>|<Length>@<BitOrder><Signed> (<Factor>,<Offset>) [<Min>,<Max>] "<Unit>" <...Receivers>`)
+SG_ <SignalName> [<MultiplexIndicator>] : <StartBit>|<Length>@<BitOrder><Signed> (<Factor>,<Offset>) [<Min>,<Max>] "<Unit>" <...Receivers>`)
 	}
 
-	if lineParts[2] != ":" {
+	if lineParts[2] != ":" && (len(lineParts) < 8 || lineParts[3] != ":") {
 		return errorAtLine(s.lineNumber, "signal line has not a ':' between <SignalName> and <StartBit>")
 	}
 
 	return nil
 }
 
+// parseMultiplexIndicator parses 'M' for multiplexors, 'm<Value>' for
+// multiplexed signals and 'm<Value>M' for extended multiplexing, where a
+// multiplexed signal is itself a multiplexor.
+func (s *Signal) parseMultiplexIndicator(indicator string) error {
+	if indicator == "M" {
+		s.IsMultiplexor = true
+		return nil
+	}
+
+	if !strings.HasPrefix(indicator, "m") {
+		return errorAtLine(s.lineNumber, "signal line has invalid multiplex indicator: %s", indicator)
+	}
+
+	valueStr := strings.TrimPrefix(indicator, "m")
+	if strings.HasSuffix(valueStr, "M") {
+		s.IsMultiplexor = true
+		valueStr = strings.TrimSuffix(valueStr, "M")
+	}
+
+	value, err := strconv.ParseUint(valueStr, 10, 64)
+	if err != nil {
+		return errorAtLine(s.lineNumber, "signal line has invalid multiplex indicator: %s", indicator)
+	}
+
+	s.MultiplexValues = []MultiplexRange{{Min: value, Max: value}}
+
+	return nil
+}
+
 func (s *Signal) parseBitInfo(message *Message, signalBitInfo string) error {
 	signalBitFirstSplit := strings.Split(signalBitInfo, "@")
 	if len(signalBitFirstSplit) != 2 {
@@ -116,8 +256,13 @@ func (s *Signal) parseBitInfo(message *Message, signalBitInfo string) error {
 		return errorAtLine(s.lineNumber, "signal line has invalid bit order and signed: %s", signalOtherInfo)
 	}
 
-	signalEndianness, err := strconv.ParseUint(string(signalOtherInfo[0]), 10, 1)
-	if err != nil {
+	var signalEndianness Endianness
+	switch signalOtherInfo[0] {
+	case '0':
+		signalEndianness = BigEndian
+	case '1':
+		signalEndianness = LittleEndian
+	default:
 		return errorAtLine(s.lineNumber, "signal line has invalid bit order: %s", string(signalOtherInfo[0]))
 	}
 	var signalSigned bool
@@ -130,13 +275,13 @@ func (s *Signal) parseBitInfo(message *Message, signalBitInfo string) error {
 		return errorAtLine(s.lineNumber, "signal line has invalid signed: %s", string(signalOtherInfo[1]))
 	}
 
-	s.Endianness = Endianness(signalEndianness)
+	s.Endianness = signalEndianness
 	s.Length = uint8(signalLength)
 	s.Signed = signalSigned
-	s.StartBit = uint8(signalStartBit)
-	message.signalsTotalLength += uint8(signalLength)
-	message.signalsBitPositions[signalStartBit] = len(message.Signals)
-	message.signalsBitPositions[signalStartBit+signalLength-1] = len(message.Signals)
+	s.StartBit = uint16(signalStartBit)
+	if !s.IsMultiplexed() {
+		message.signalsTotalLength += uint16(signalLength)
+	}
 
 	return nil
 }
@@ -7,16 +7,16 @@ import (
 )
 
 func TestParseSignalBitInfo(t *testing.T) {
-	t.Run("should parse bit info with little endian", func(t *testing.T) {
+	t.Run("should parse bit info with big endian", func(t *testing.T) {
 		a := assert.New(t)
 
 		signal := &Signal{}
 		message := &Message{}
-		err := signal.parseBitInfo(message, "0|16@0+")
+		err := signal.parseBitInfo(message, "7|16@0+")
 		a.Nil(err)
-		a.Equal(uint8(0), signal.StartBit)
+		a.Equal(uint16(7), signal.StartBit)
 		a.Equal(uint8(16), signal.Length)
-		a.Equal(LittleEndian, signal.Endianness)
+		a.Equal(BigEndian, signal.Endianness)
 		a.False(signal.Signed)
 	})
 
@@ -27,9 +27,9 @@ func TestParseSignalBitInfo(t *testing.T) {
 		message := &Message{}
 		err := signal.parseBitInfo(message, "0|16@1-")
 		a.Nil(err)
-		a.Equal(uint8(0), signal.StartBit)
+		a.Equal(uint16(0), signal.StartBit)
 		a.Equal(uint8(16), signal.Length)
-		a.Equal(BigEndian, signal.Endianness)
+		a.Equal(LittleEndian, signal.Endianness)
 		a.True(signal.Signed)
 	})
 
@@ -105,6 +105,116 @@ func TestParseSignalBitInfo(t *testing.T) {
 
 }
 
+func TestSignalBitPositions(t *testing.T) {
+	t.Run("should place little endian signal from start bit upwards", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{
+			StartBit:   4,
+			Length:     8,
+			Endianness: LittleEndian,
+		}
+		a.Equal([]int{4, 5, 6, 7, 8, 9, 10, 11}, signal.bitPositions())
+	})
+
+	t.Run("should place big endian signal with sawtooth numbering", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{
+			StartBit:   3,
+			Length:     8,
+			Endianness: BigEndian,
+		}
+		a.Equal([]int{3, 2, 1, 0, 15, 14, 13, 12}, signal.bitPositions())
+	})
+
+	t.Run("should place byte aligned big endian signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{
+			StartBit:   7,
+			Length:     16,
+			Endianness: BigEndian,
+		}
+		positions := signal.bitPositions()
+		a.Len(positions, 16)
+		a.Equal(7, positions[0])
+		a.Equal(0, positions[7])
+		a.Equal(15, positions[8])
+		a.Equal(8, positions[15])
+	})
+}
+
+func TestParseSignalMultiplexIndicator(t *testing.T) {
+	t.Run("should parse multiplexor", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{}
+		err := signal.parseMultiplexIndicator("M")
+		a.Nil(err)
+		a.True(signal.IsMultiplexor)
+		a.False(signal.IsMultiplexed())
+	})
+
+	t.Run("should parse multiplexed signal", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{}
+		err := signal.parseMultiplexIndicator("m12")
+		a.Nil(err)
+		a.False(signal.IsMultiplexor)
+		a.True(signal.IsMultiplexed())
+		a.Equal([]MultiplexRange{{Min: 12, Max: 12}}, signal.MultiplexValues)
+	})
+
+	t.Run("should parse extended multiplexor", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{}
+		err := signal.parseMultiplexIndicator("m3M")
+		a.Nil(err)
+		a.True(signal.IsMultiplexor)
+		a.Equal([]MultiplexRange{{Min: 3, Max: 3}}, signal.MultiplexValues)
+	})
+
+	t.Run("should return error for invalid indicator", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{}
+		err := signal.parseMultiplexIndicator("mx")
+		a.Error(err)
+		a.Contains(err.Error(), "invalid multiplex indicator")
+
+		err = signal.parseMultiplexIndicator("X")
+		a.Error(err)
+	})
+}
+
+func TestNewSignalFromLine(t *testing.T) {
+	t.Run("should parse multiplexed signal line", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{}
+		signal, err := NewSignalFromLine(message, `SG_ Temp m3 : 8|16@1+ (0.1,0) [0|100] "degC" BMS`, 0)
+		a.Nil(err)
+		a.Equal("Temp", signal.Name)
+		a.Equal(uint16(8), signal.StartBit)
+		a.Equal(uint8(16), signal.Length)
+		a.Equal([]MultiplexRange{{Min: 3, Max: 3}}, signal.MultiplexValues)
+		a.Equal([]Node{"BMS"}, signal.Receivers)
+		a.Equal(uint16(0), message.signalsTotalLength)
+	})
+
+	t.Run("should return error for missing ':' after multiplex indicator", func(t *testing.T) {
+		a := assert.New(t)
+
+		message := &Message{}
+		signal, err := NewSignalFromLine(message, `SG_ Temp m3 8|16@1+ (0.1,0) [0|100] "degC" BMS`, 0)
+		a.Error(err)
+		a.Nil(signal)
+	})
+}
+
 func TestParseSignalFactorOffset(t *testing.T) {
 	t.Run("should parse factor and offset", func(t *testing.T) {
 		a := assert.New(t)
@@ -269,12 +379,12 @@ func TestSignalValidate(t *testing.T) {
 		a.Nil(err)
 	})
 
-	t.Run("should return error when start bit >= 64", func(t *testing.T) {
+	t.Run("should return error when start bit >= 512", func(t *testing.T) {
 		a := assert.New(t)
 
 		signal := &Signal{
 			Name:     "Speed",
-			StartBit: 64,
+			StartBit: 512,
 			Length:   2,
 			Factor:   0.1,
 		}
@@ -297,6 +407,26 @@ func TestSignalValidate(t *testing.T) {
 		a.Error(err)
 	})
 
+	t.Run("should return error for IEEE signals of the wrong length", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{Name: "AccelX", Length: 16, Factor: 1, ValueType: FloatValue}
+		a.EqualError(signal.Validate(), "line 0: float signal length must be 32")
+
+		signal = &Signal{Name: "Uptime", Length: 32, Factor: 1, ValueType: DoubleValue}
+		a.EqualError(signal.Validate(), "line 0: double signal length must be 64")
+
+		signal = &Signal{Name: "Uptime", Length: 64, Factor: 1, ValueType: DoubleValue}
+		a.Nil(signal.Validate())
+	})
+
+	t.Run("should return error for IEEE multiplexors", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := &Signal{Name: "Mode", Length: 32, Factor: 1, ValueType: FloatValue, IsMultiplexor: true}
+		a.EqualError(signal.Validate(), "line 0: multiplexor signal must be an integer")
+	})
+
 	t.Run("should return error when factor is zero", func(t *testing.T) {
 		a := assert.New(t)
 
@@ -311,3 +441,73 @@ func TestSignalValidate(t *testing.T) {
 		a.Error(err)
 	})
 }
+
+func TestSignalStartValue(t *testing.T) {
+	t.Run("should return the GenSigStartValue attribute", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal(int64(8000), (&Signal{Attributes: Attributes{"GenSigStartValue": int64(8000)}}).StartValue())
+		a.Equal(int64(-5), (&Signal{Attributes: Attributes{"GenSigStartValue": -5.0}}).StartValue())
+	})
+
+	t.Run("should round float start values", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal(int64(3), (&Signal{Attributes: Attributes{"GenSigStartValue": 2.5}}).StartValue())
+	})
+
+	t.Run("should return 0 without start value", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal(int64(0), (&Signal{}).StartValue())
+	})
+}
+
+func TestSignalInvalidValues(t *testing.T) {
+	t.Run("should return the raw invalid and not available values", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := Signal{
+			Length:     8,
+			Attributes: Attributes{"GenSigInvalidValue": int64(0xfe), "GenSigSNA": int64(0xff)},
+		}
+
+		invalid, ok := signal.InvalidValue()
+		a.True(ok)
+		a.Equal(uint64(0xfe), invalid)
+
+		notAvailable, ok := signal.NotAvailableValue()
+		a.True(ok)
+		a.Equal(uint64(0xff), notAvailable)
+	})
+
+	t.Run("should truncate negative values to the signal bits", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := Signal{
+			Length:     12,
+			Signed:     true,
+			Attributes: Attributes{"GenSigSNA": int64(-1)},
+		}
+
+		notAvailable, ok := signal.NotAvailableValue()
+		a.True(ok)
+		a.Equal(uint64(0xfff), notAvailable)
+
+		signal.Length = 64
+		notAvailable, ok = signal.NotAvailableValue()
+		a.True(ok)
+		a.Equal(uint64(0xffffffffffffffff), notAvailable)
+	})
+
+	t.Run("should report missing values", func(t *testing.T) {
+		a := assert.New(t)
+
+		signal := Signal{Length: 8}
+
+		_, ok := signal.InvalidValue()
+		a.False(ok)
+		_, ok = signal.NotAvailableValue()
+		a.False(ok)
+	})
+}
@@ -1,12 +1,32 @@
 package vera
 
 type Config struct {
+	// Nodes are the ECUs declared by BU_. When there are any, transmitters
+	// and receivers must be among them.
+	Nodes    []Node
 	Messages []Message
 	Topics   []SignalTopic
+	// Comment is the CM_ comment of the whole network.
+	Comment string
+	// NodeComments maps nodes to their CM_ BU_ comments.
+	NodeComments map[Node]string
+	// AttributeDefinitions are the BA_DEF_ definitions of the attributes
+	// set on the network, nodes, messages and signals.
+	AttributeDefinitions []AttributeDefinition
+	// Attributes are the network attributes, NodeAttributes the attributes
+	// of each node.
+	Attributes     Attributes
+	NodeAttributes map[Node]Attributes
+
+	// file is the ParseOptions.File the configuration was parsed from.
+	file string
 }
 
 type Node string
 
+// unspecifiedNode is the placeholder DBC files use where no node applies.
+const unspecifiedNode Node = "Vector__XXX"
+
 type Endianness uint
 
 const (
@@ -14,7 +34,32 @@ const (
 	BigEndian
 )
 
+// ValueType tells how the raw bits of a signal are read, as declared by
+// SIG_VALTYPE_.
+type ValueType uint
+
+const (
+	// IntegerValue signals are scaled integers, the default.
+	IntegerValue ValueType = iota
+	// FloatValue signals are 32 bits IEEE 754 floats.
+	FloatValue
+	// DoubleValue signals are 64 bits IEEE 754 doubles.
+	DoubleValue
+)
+
 type SignalTopic struct {
 	Topic  string
 	Signal string
+
+	lineNumber int
+}
+
+// MultiplexRange is an inclusive range of multiplexor values.
+type MultiplexRange struct {
+	Min uint64
+	Max uint64
+}
+
+func (r MultiplexRange) Contains(value uint64) bool {
+	return value >= r.Min && value <= r.Max
 }
@@ -5,3 +5,73 @@ import "strings"
 func replaceNewLineCharacters(s string) string {
 	return strings.ReplaceAll(s, "\r\n", "\n")
 }
+
+// splitFields splits s around whitespace like strings.Fields, but keeps
+// double-quoted strings together as a single field, quotes included.
+// Quotes escaped with a backslash do not end a quoted string.
+func splitFields(s string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuotes := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+			current.WriteRune(r)
+		case inQuotes && r == '\\':
+			escaped = true
+			current.WriteRune(r)
+		case r == '"':
+			inQuotes = !inQuotes
+			current.WriteRune(r)
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+
+	return fields
+}
+
+func isQuoted(s string) bool {
+	return len(s) >= 2 && strings.HasPrefix(s, "\"") && strings.HasSuffix(s, "\"")
+}
+
+// unquote strips the quotes around s and unescapes the quotes within.
+func unquote(s string) string {
+	return strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`)
+}
+
+// quote is the inverse of unquote.
+func quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// hasOpenQuote reports whether s ends inside a double-quoted string.
+func hasOpenQuote(s string) bool {
+	inQuotes := false
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		}
+	}
+
+	return inQuotes
+}
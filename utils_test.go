@@ -57,3 +57,65 @@ func TestReplaceNewLineCharacters(t *testing.T) {
 		a.Equal(expected, result)
 	})
 }
+
+func TestSplitFields(t *testing.T) {
+	t.Run("should split on whitespace", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal([]string{"VAL_", "123", "Gear"}, splitFields("VAL_  123\tGear"))
+	})
+
+	t.Run("should keep quoted strings together", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal(
+			[]string{"VAL_", "123", "Gear", "0", `"Not available"`, "1", `"First"`},
+			splitFields(`VAL_ 123 Gear 0 "Not available" 1 "First"`),
+		)
+	})
+
+	t.Run("should keep empty quoted strings", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal([]string{"0", `""`}, splitFields(`0 ""`))
+	})
+
+	t.Run("should not end quoted strings on escaped quotes", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal([]string{"CM_", `"A \"quoted\" word"`}, splitFields(`CM_ "A \"quoted\" word"`))
+	})
+
+	t.Run("should keep newlines in quoted strings", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal([]string{"CM_", "BO_", "1", "\"First\nSecond\""}, splitFields("CM_ BO_\n1 \"First\nSecond\""))
+	})
+
+	t.Run("should return nil for blank string", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Nil(splitFields(" \t "))
+	})
+}
+
+func TestQuote(t *testing.T) {
+	t.Run("should escape and unescape quotes", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.Equal(`"A \"quoted\" word"`, quote(`A "quoted" word`))
+		a.Equal(`A "quoted" word`, unquote(`"A \"quoted\" word"`))
+		a.Equal("", unquote(`""`))
+	})
+}
+
+func TestHasOpenQuote(t *testing.T) {
+	t.Run("should tell whether a quoted string is still open", func(t *testing.T) {
+		a := assert.New(t)
+
+		a.False(hasOpenQuote(`CM_ BO_ 1 "Comment";`))
+		a.True(hasOpenQuote(`CM_ BO_ 1 "First line`))
+		a.True(hasOpenQuote(`CM_ BO_ 1 "Escaped \"`))
+		a.False(hasOpenQuote(`CM_ BO_ 1 "Escaped \""`))
+	})
+}
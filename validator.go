@@ -1,23 +1,40 @@
 package vera
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
 
+// Validate checks the whole configuration, returning Diagnostics with every
+// problem found.
 func (c *Config) Validate() error {
+	var diagnostics Diagnostics
+
 	topicsMap := make(map[string]string)
 	for i, t := range c.Topics {
 		if err := t.Validate(); err != nil {
-			return fmt.Errorf("topic Nº%d: %w", i, err)
+			diagnostics.add(errorAtLine(t.lineNumber, "topic Nº%d: %s", i, err.Error()), CodeInvalidTopic, c.file)
+			continue
 		}
 
 		if _, ok := topicsMap[t.Signal]; ok {
-			return fmt.Errorf("duplicate signal topic: %s", t.Topic)
+			diagnostics.add(errorAtLine(t.lineNumber, "duplicate signal topic: %s", t.Topic), CodeInvalidTopic, c.file)
+			continue
 		}
 		topicsMap[t.Signal] = t.Topic
 	}
 
+	for _, err := range c.validateAttributes() {
+		diagnostics.add(err, CodeInvalidAttribute, c.file)
+	}
+
 	for i := range c.Messages {
-		if err := c.Messages[i].Validate(); err != nil {
-			return err
+		for _, err := range c.Messages[i].validate() {
+			diagnostics.add(err, CodeInvalidMessage, c.file)
+		}
+
+		for _, err := range c.validateNodes(&c.Messages[i]) {
+			diagnostics.add(err, CodeUndeclaredNode, c.file)
 		}
 
 		for j := range c.Messages[i].Signals {
@@ -27,7 +44,101 @@ func (c *Config) Validate() error {
 		}
 	}
 
-	return nil
+	return diagnostics.Err()
+}
+
+// validateNodes checks that the transmitter and receivers of a message are
+// declared by BU_, if the configuration declares any node.
+func (c *Config) validateNodes(m *Message) []error {
+	if len(c.Nodes) == 0 {
+		return nil
+	}
+
+	var errs []error
+	if !c.isNodeDeclared(m.Transmitter) {
+		errs = append(errs, errorAtLine(m.lineNumber, "message transmitter '%s' is not declared in BU_", m.Transmitter))
+	}
+
+	for _, s := range m.Signals {
+		for _, r := range s.Receivers {
+			if !c.isNodeDeclared(r) {
+				errs = append(errs, errorAtLine(s.lineNumber, "signal receiver '%s' is not declared in BU_", r))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateAttributes checks the attribute definitions, then that every
+// attribute value is defined for its object and matches its definition.
+func (c *Config) validateAttributes() []error {
+	var errs []error
+	for i := range c.AttributeDefinitions {
+		if err := c.AttributeDefinitions[i].Validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, err := range c.checkAttributes(c.Attributes, NetworkAttribute) {
+		errs = append(errs, fmt.Errorf("network %w", err))
+	}
+
+	nodes := make([]Node, 0, len(c.NodeAttributes))
+	for n := range c.NodeAttributes {
+		nodes = append(nodes, n)
+	}
+	slices.Sort(nodes)
+	for _, n := range nodes {
+		for _, err := range c.checkAttributes(c.NodeAttributes[n], NodeAttribute) {
+			errs = append(errs, fmt.Errorf("node '%s' %w", n, err))
+		}
+	}
+
+	for _, m := range c.Messages {
+		for _, err := range c.checkAttributes(m.Attributes, MessageAttribute) {
+			errs = append(errs, errorAtLine(m.lineNumber, "message '%s' %s", m.Name, err.Error()))
+		}
+
+		for _, s := range m.Signals {
+			for _, err := range c.checkAttributes(s.Attributes, SignalAttribute) {
+				errs = append(errs, errorAtLine(s.lineNumber, "signal '%s' %s", s.Name, err.Error()))
+			}
+		}
+	}
+
+	return errs
+}
+
+// checkAttributes checks the attributes of an object of the given kind,
+// returning a problem per invalid attribute.
+func (c *Config) checkAttributes(attributes Attributes, object AttributeObject) []error {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var errs []error
+	for _, name := range names {
+		definition := c.AttributeDefinition(name)
+		switch {
+		case definition == nil:
+			errs = append(errs, fmt.Errorf("attribute '%s' is not defined in BA_DEF_", name))
+		case definition.Object != object:
+			errs = append(errs, fmt.Errorf("attribute '%s' is not defined for this object", name))
+		default:
+			if err := definition.checkValue(attributes[name]); err != nil {
+				errs = append(errs, fmt.Errorf("attribute '%s' has invalid value: %w", name, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+func (c *Config) isNodeDeclared(n Node) bool {
+	return n == unspecifiedNode || slices.Contains(c.Nodes, n)
 }
 
 func (t *SignalTopic) Validate() error {
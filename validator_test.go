@@ -1,6 +1,8 @@
 package vera
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -98,6 +100,55 @@ func TestConfigValidate(t *testing.T) {
 		a.NotNil(err)
 		a.Contains(err.Error(), "message DLC must be a number between 1 and 8")
 	})
+
+	t.Run("should return error for undeclared transmitter", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BU_: Engine DriverGateway
+
+BO_ 123 EngineSpeed: 1 Engnie
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" DriverGateway`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal("line 2: message transmitter 'Engnie' is not declared in BU_", err.Error())
+	})
+
+	t.Run("should return error for undeclared receiver", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BU_: Engine DriverGateway
+
+BO_ 123 EngineSpeed: 2 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" DriverGateway
+	SG_ Gear : 8|8@1+ (1,0) [0|255] "" DriverGateway,DriverGatway`))
+		a.Nil(err)
+
+		err = config.Validate()
+		a.NotNil(err)
+		a.Equal("line 4: signal receiver 'DriverGatway' is not declared in BU_", err.Error())
+	})
+
+	t.Run("should accept the Vector__XXX placeholder", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BU_: Engine
+
+BO_ 123 EngineSpeed: 1 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" Vector__XXX`))
+		a.Nil(err)
+		a.Nil(config.Validate())
+	})
+
+	t.Run("should not check nodes without a BU_ list", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(`BO_ 123 EngineSpeed: 1 Engine
+	SG_ Speed : 0|8@1+ (1,0) [0|255] "km/h" DriverGateway`))
+		a.Nil(err)
+		a.Nil(config.Validate())
+	})
 }
 
 func TestSignalTopicValidate(t *testing.T) {
@@ -149,3 +200,50 @@ func TestSignalTopicValidate(t *testing.T) {
 		a.Error(err)
 	})
 }
+
+func TestConfigValidate_AllErrors(t *testing.T) {
+	t.Run("should report every problem at once", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := ParseWith(strings.NewReader(`BU_: Engine VCU
+BO_ 123 EngineSpeed: 1 Engine
+	SG_ Speed : 0|16@1+ (1,0) [0|255] "km/h" VCU
+BO_ 124 EngineStatus: 1 Engnie
+	SG_ Status : 0|8@1+ (1,0) [0|255] "" VCU,Dashboard
+TP_ Speed vehicle/speed
+TP_ Speed vehicle/rpm`), ParseOptions{File: "car.dbc"})
+		a.Nil(err)
+
+		err = config.Validate()
+		var diagnostics Diagnostics
+		a.True(errors.As(err, &diagnostics))
+		a.Equal(Diagnostics{
+			{File: "car.dbc", Line: 7, Severity: SeverityError, Code: CodeInvalidTopic, Message: "duplicate signal topic: vehicle/rpm"},
+			{File: "car.dbc", Line: 2, Severity: SeverityError, Code: CodeInvalidMessage, Message: "sum of signal lengths must be less than or equal to (message DLC * 8)"},
+			{File: "car.dbc", Line: 2, Severity: SeverityError, Code: CodeInvalidMessage, Message: "signal 'Speed' does not fit in the message payload"},
+			{File: "car.dbc", Line: 4, Severity: SeverityError, Code: CodeUndeclaredNode, Message: "message transmitter 'Engnie' is not declared in BU_"},
+			{File: "car.dbc", Line: 5, Severity: SeverityError, Code: CodeUndeclaredNode, Message: "signal receiver 'Dashboard' is not declared in BU_"},
+		}, diagnostics)
+	})
+
+	t.Run("should report every problem of a message", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := ParseWith(strings.NewReader(`BO_ 123 EngineSpeed: 3 Engine
+	SG_ Speed : 0|8@1+ (0,0) [0|255] "km/h" VCU
+	SG_ Torque : 4|8@1+ (1,0) [0|255] "Nm" VCU
+	SG_ Gear : 6|4@1+ (0,0) [0|15] "" VCU`), ParseOptions{File: "car.dbc"})
+		a.Nil(err)
+
+		err = config.Validate()
+		var diagnostics Diagnostics
+		a.True(errors.As(err, &diagnostics))
+		a.Equal(Diagnostics{
+			{File: "car.dbc", Line: 1, Severity: SeverityError, Code: CodeInvalidMessage, Message: "signals 'Speed' and 'Torque' cannot overlap"},
+			{File: "car.dbc", Line: 1, Severity: SeverityError, Code: CodeInvalidMessage, Message: "signals 'Speed' and 'Gear' cannot overlap"},
+			{File: "car.dbc", Line: 1, Severity: SeverityError, Code: CodeInvalidMessage, Message: "signals 'Torque' and 'Gear' cannot overlap"},
+			{File: "car.dbc", Line: 2, Severity: SeverityError, Code: CodeInvalidSignal, Message: "signal factor cannot be zero"},
+			{File: "car.dbc", Line: 4, Severity: SeverityError, Code: CodeInvalidSignal, Message: "signal factor cannot be zero"},
+		}, diagnostics)
+	})
+}
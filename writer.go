@@ -0,0 +1,293 @@
+package vera
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// WriteDBC writes the configuration as a DBC file, which Parse reads back
+// to the same configuration.
+func (c *Config) WriteDBC(w io.Writer) error {
+	var b strings.Builder
+
+	b.WriteString("VERSION \"\"\n\nNS_ :\n\nBS_:\n\n")
+	b.WriteString("BU_:")
+	for _, n := range c.Nodes {
+		fmt.Fprintf(&b, " %s", n)
+	}
+	b.WriteString("\n")
+
+	for i := range c.Messages {
+		b.WriteString("\n")
+		c.Messages[i].writeDBC(&b)
+	}
+
+	if comments := c.commentLines(); len(comments) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(comments, "\n"))
+	}
+
+	if attributes := c.attributeLines(); len(attributes) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", strings.Join(attributes, "\n"))
+	}
+
+	if len(c.Topics) > 0 {
+		b.WriteString("\n")
+	}
+	for _, t := range c.Topics {
+		fmt.Fprintf(&b, "TP_ %s %s\n", t.Signal, t.Topic)
+	}
+
+	var valueDescriptions, valueTypes, multiplexValues []string
+	for i := range c.Messages {
+		m := &c.Messages[i]
+		for j := range m.Signals {
+			s := &m.Signals[j]
+			if len(s.ValueDescriptions) > 0 {
+				valueDescriptions = append(valueDescriptions, s.valueDescriptionsLine(m))
+			}
+			if s.ValueType != IntegerValue {
+				valueTypes = append(valueTypes, fmt.Sprintf("SIG_VALTYPE_ %d %s : %d;", m.dbcID(), s.Name, s.ValueType))
+			}
+			if m.needsMultiplexValues(s) {
+				multiplexValues = append(multiplexValues, s.multiplexValuesLine(m))
+			}
+		}
+	}
+
+	for _, lines := range [][]string{valueDescriptions, valueTypes, multiplexValues} {
+		if len(lines) > 0 {
+			fmt.Fprintf(&b, "\n%s\n", strings.Join(lines, "\n"))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (c *Config) commentLines() []string {
+	var lines []string
+
+	if c.Comment != "" {
+		lines = append(lines, fmt.Sprintf("CM_ %s;", quote(c.Comment)))
+	}
+
+	nodes := make([]Node, 0, len(c.NodeComments))
+	for n := range c.NodeComments {
+		nodes = append(nodes, n)
+	}
+	slices.Sort(nodes)
+	for _, n := range nodes {
+		lines = append(lines, fmt.Sprintf("CM_ BU_ %s %s;", n, quote(c.NodeComments[n])))
+	}
+
+	for _, m := range c.Messages {
+		if m.Comment != "" {
+			lines = append(lines, fmt.Sprintf("CM_ BO_ %d %s;", m.dbcID(), quote(m.Comment)))
+		}
+		for _, s := range m.Signals {
+			if s.Comment != "" {
+				lines = append(lines, fmt.Sprintf("CM_ SG_ %d %s %s;", m.dbcID(), s.Name, quote(s.Comment)))
+			}
+		}
+	}
+
+	return lines
+}
+
+// attributeLines returns the BA_DEF_, BA_DEF_DEF_ and BA_ lines. Values equal
+// to the default of their attribute are left out, Parse setting them back.
+func (c *Config) attributeLines() []string {
+	var lines []string
+
+	for _, d := range c.AttributeDefinitions {
+		line := "BA_DEF_ "
+		if d.Object != NetworkAttribute {
+			line += string(d.Object) + " "
+		}
+		line += fmt.Sprintf("%s %s", quote(d.Name), d.Type)
+
+		switch d.Type {
+		case AttributeInt, AttributeHex, AttributeFloat:
+			line += fmt.Sprintf(" %s %s", strconv.FormatFloat(d.Min, 'f', -1, 64), strconv.FormatFloat(d.Max, 'f', -1, 64))
+		case AttributeEnum:
+			values := make([]string, len(d.EnumValues))
+			for i, v := range d.EnumValues {
+				values[i] = quote(v)
+			}
+			line += " " + strings.Join(values, ",")
+		}
+
+		lines = append(lines, line+";")
+	}
+
+	for _, d := range c.AttributeDefinitions {
+		if d.Default != nil {
+			lines = append(lines, fmt.Sprintf("BA_DEF_DEF_ %s %s;", quote(d.Name), d.formatValue(d.Default)))
+		}
+	}
+
+	lines = append(lines, c.attributeValueLines(c.Attributes, "")...)
+
+	nodes := make([]Node, 0, len(c.NodeAttributes))
+	for n := range c.NodeAttributes {
+		nodes = append(nodes, n)
+	}
+	slices.Sort(nodes)
+	for _, n := range nodes {
+		lines = append(lines, c.attributeValueLines(c.NodeAttributes[n], fmt.Sprintf("BU_ %s ", n))...)
+	}
+
+	for _, m := range c.Messages {
+		lines = append(lines, c.attributeValueLines(m.Attributes, fmt.Sprintf("BO_ %d ", m.dbcID()))...)
+		for _, s := range m.Signals {
+			lines = append(lines, c.attributeValueLines(s.Attributes, fmt.Sprintf("SG_ %d %s ", m.dbcID(), s.Name))...)
+		}
+	}
+
+	return lines
+}
+
+// attributeValueLines returns the BA_ lines of the attributes of an object,
+// target being the object as written before the value.
+func (c *Config) attributeValueLines(attributes Attributes, target string) []string {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var lines []string
+	for _, name := range names {
+		definition := c.AttributeDefinition(name)
+		if definition == nil {
+			definition = &AttributeDefinition{Name: name}
+		}
+
+		value := attributes[name]
+		if definition.Default != nil && value == definition.Default {
+			continue
+		}
+
+		lines = append(lines, fmt.Sprintf("BA_ %s %s%s;", quote(name), target, definition.formatValue(value)))
+	}
+
+	return lines
+}
+
+func (m *Message) writeDBC(b *strings.Builder) {
+	transmitter := m.Transmitter
+	if transmitter == "" {
+		transmitter = unspecifiedNode
+	}
+	fmt.Fprintf(b, "BO_ %d %s: %d %s\n", m.dbcID(), m.Name, m.DLC, transmitter)
+
+	for i := range m.Signals {
+		s := &m.Signals[i]
+
+		fmt.Fprintf(b, "\tSG_ %s ", s.Name)
+		if indicator := s.multiplexIndicator(); indicator != "" {
+			fmt.Fprintf(b, "%s ", indicator)
+		}
+
+		byteOrder := '1'
+		if s.Endianness == BigEndian {
+			byteOrder = '0'
+		}
+		sign := '+'
+		if s.Signed {
+			sign = '-'
+		}
+
+		fmt.Fprintf(b, ": %d|%d@%c%c (%s,%s) [%s|%s] \"%s\"",
+			s.StartBit, s.Length, byteOrder, sign,
+			formatFloat(s.Factor), formatFloat(s.Offset),
+			formatFloat(s.Min), formatFloat(s.Max),
+			s.Unit,
+		)
+
+		if len(s.Receivers) > 0 {
+			receivers := make([]string, len(s.Receivers))
+			for j, r := range s.Receivers {
+				receivers[j] = string(r)
+			}
+			fmt.Fprintf(b, " %s", strings.Join(receivers, ","))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// multiplexIndicator returns the indicator written between the signal name
+// and ':'. Multiplexed signals get the lowest of their values, the full
+// ranges being written by SG_MUL_VAL_ when needed.
+func (s *Signal) multiplexIndicator() string {
+	if !s.IsMultiplexed() {
+		if s.IsMultiplexor {
+			return "M"
+		}
+		return ""
+	}
+
+	indicator := fmt.Sprintf("m%d", s.MultiplexValues[0].Min)
+	if s.IsMultiplexor {
+		indicator += "M"
+	}
+
+	return indicator
+}
+
+// needsMultiplexValues reports whether the multiplexing of a signal cannot
+// be told from its indicator alone: it has ranges, more than one value or a
+// multiplexor that resolveMultiplexors would not pick.
+func (m *Message) needsMultiplexValues(s *Signal) bool {
+	if !s.IsMultiplexed() {
+		return false
+	}
+
+	if len(s.MultiplexValues) != 1 || s.MultiplexValues[0].Min != s.MultiplexValues[0].Max {
+		return true
+	}
+
+	var multiplexors []string
+	for _, other := range m.Signals {
+		if other.IsMultiplexor && !other.IsMultiplexed() {
+			multiplexors = append(multiplexors, other.Name)
+		}
+	}
+
+	return len(multiplexors) != 1 || multiplexors[0] != s.Multiplexor
+}
+
+func (s *Signal) valueDescriptionsLine(m *Message) string {
+	values := make([]int64, 0, len(s.ValueDescriptions))
+	for value := range s.ValueDescriptions {
+		values = append(values, value)
+	}
+	slices.Sort(values)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "VAL_ %d %s", m.dbcID(), s.Name)
+	for _, value := range values {
+		fmt.Fprintf(&b, " %d %s", value, quote(s.ValueDescriptions[value]))
+	}
+	b.WriteString(" ;")
+
+	return b.String()
+}
+
+func (s *Signal) multiplexValuesLine(m *Message) string {
+	ranges := make([]string, len(s.MultiplexValues))
+	for i, r := range s.MultiplexValues {
+		ranges[i] = fmt.Sprintf("%d-%d", r.Min, r.Max)
+	}
+
+	return fmt.Sprintf("SG_MUL_VAL_ %d %s %s %s;", m.dbcID(), s.Name, s.Multiplexor, strings.Join(ranges, ", "))
+}
+
+// formatFloat writes f with the fewest digits that parse back to it,
+// without exponent.
+func formatFloat(f float32) string {
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
@@ -0,0 +1,215 @@
+package vera
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const roundTripConfig = `VERSION ""
+
+BU_: Engine Inverter BMS Logger DriverGateway EngineGateway
+
+BO_ 123 EngineSpeed: 3 Engine
+	SG_ EngineSpeed : 0|16@1+ (0.1,0) [0|8000] "RPM" DriverGateway
+	SG_ OilTemperature : 16|8@1- (1,-40) [-40|150] "ºC" DriverGateway,EngineGateway
+
+BO_ 0x7C InverterStatus: 4 Inverter
+	SG_ Torque : 7|16@0+ (1,0) [0|65535] "Nm"
+	SG_ Status : 27|4@0+ (1,0) [0|15] "" DriverGateway
+
+BO_ 200 Diagnostics: 8 BMS
+	SG_ Service M : 0|8@1+ (1,0) [0|255] "" DriverGateway
+	SG_ Subservice m1M : 8|8@1+ (1,0) [0|255] "" DriverGateway
+	SG_ Counter m1 : 16|16@1+ (1,0) [0|65535] "" DriverGateway
+	SG_ RawData m2 : 8|32@1+ (1,0) [0|4294967295] "" DriverGateway
+	SG_ Cell m3 : 8|16@1+ (0.001,0) [0|65.535] "V" DriverGateway
+
+BO_ 128 FdTelemetry: 64 Logger
+	SG_ Last : 496|16@1+ (1,0) [0|65535] "" DriverGateway
+
+BO_ 2147483904 ExtendedFrame: 1 Engine
+	SG_ Full : 0|8@1- (0.5,-10) [-74|53.5] "" DriverGateway
+
+BO_ 129 ImuData: 4 Engine
+	SG_ AccelX : 0|32@1- (1,0) [-100|100] "m/s2" DriverGateway
+
+BO_ 130 LoggerTime: 8 Logger
+	SG_ Uptime : 0|64@1- (1,0) [0|1000000] "s" DriverGateway
+
+CM_ "Test network";
+CM_ BU_ BMS "Battery management system";
+CM_ BO_ 123 "Engine speed,
+sent every 10 ms";
+CM_ SG_ 200 Service "Selects the \"service\"";
+
+BA_DEF_ "BusType" STRING;
+BA_DEF_ BU_ "ILUsed" ENUM "No","Yes";
+BA_DEF_ BO_ "GenMsgCycleTime" INT 0 10000;
+BA_DEF_ SG_ "GenSigStartValue" FLOAT -1000 1000;
+BA_DEF_DEF_ "BusType" "CAN";
+BA_DEF_DEF_ "ILUsed" "No";
+BA_DEF_DEF_ "GenMsgCycleTime" 100;
+BA_ "BusType" "CAN FD";
+BA_ "ILUsed" BU_ BMS 1;
+BA_ "GenMsgCycleTime" BO_ 123 10;
+BA_ "GenMsgCycleTime" BO_ 200 100;
+BA_ "GenSigStartValue" SG_ 123 OilTemperature -40.5;
+
+TP_ EngineSpeed vehicle/engine/speed
+
+VAL_ 124 Status 15 "Fault" 0 "Off" 1 "Ready" ;
+VAL_ 2147483904 Full -1 "Invalid" ;
+
+SIG_VALTYPE_ 129 AccelX : 1;
+SIG_VALTYPE_ 130 Uptime : 2;
+
+SG_MUL_VAL_ 200 Subservice Service 1-1;
+SG_MUL_VAL_ 200 Counter Subservice 1-1, 4-6;
+SG_MUL_VAL_ 200 Cell Service 3-5;`
+
+func clearLineNumbers(config *Config) {
+	for i := range config.Messages {
+		config.Messages[i].lineNumber = 0
+		for j := range config.Messages[i].Signals {
+			config.Messages[i].Signals[j].lineNumber = 0
+		}
+	}
+	for i := range config.AttributeDefinitions {
+		config.AttributeDefinitions[i].lineNumber = 0
+	}
+	for i := range config.Topics {
+		config.Topics[i].lineNumber = 0
+	}
+}
+
+func TestConfigWriteDBC(t *testing.T) {
+	t.Run("should write a DBC parsed back to the same config", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(roundTripConfig))
+		a.Nil(err)
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+
+		reparsed, err := Parse(strings.NewReader(b.String()))
+		a.Nil(err)
+
+		clearLineNumbers(config)
+		clearLineNumbers(reparsed)
+		a.Equal(config, reparsed)
+	})
+
+	t.Run("should write a config built in Go parsed back to the same config", func(t *testing.T) {
+		a := assert.New(t)
+
+		config := &Config{
+			Messages: []Message{{
+				Name: "EngineSpeed",
+				ID:   123,
+				DLC:  2,
+				Signals: []Signal{
+					{Name: "Speed", Length: 16, Factor: 0.1, Max: 6000, Unit: "RPM"},
+				},
+			}},
+		}
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+
+		reparsed, err := Parse(strings.NewReader(b.String()))
+		a.Nil(err)
+		a.Nil(reparsed.Validate())
+
+		clearLineNumbers(reparsed)
+		reparsed.Messages[0].signalsTotalLength = 0
+		config.Messages[0].Transmitter = unspecifiedNode
+		a.Equal(config.Messages, reparsed.Messages)
+	})
+
+	t.Run("should be stable once written", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(roundTripConfig))
+		a.Nil(err)
+
+		var first strings.Builder
+		a.Nil(config.WriteDBC(&first))
+
+		reparsed, err := Parse(strings.NewReader(first.String()))
+		a.Nil(err)
+
+		var second strings.Builder
+		a.Nil(reparsed.WriteDBC(&second))
+		a.Equal(first.String(), second.String())
+	})
+
+	t.Run("should write canonical DBC", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(roundTripConfig))
+		a.Nil(err)
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+
+		dbc := b.String()
+		a.True(strings.HasPrefix(dbc, "VERSION \"\"\n\nNS_ :\n\nBS_:\n\nBU_: Engine Inverter BMS Logger DriverGateway EngineGateway\n"))
+		a.Contains(dbc, "BO_ 124 InverterStatus: 4 Inverter\n\tSG_ Torque : 7|16@0+ (1,0) [0|65535] \"Nm\"\n")
+		a.Contains(dbc, "\tSG_ OilTemperature : 16|8@1- (1,-40) [-40|150] \"ºC\" DriverGateway,EngineGateway\n")
+		a.Contains(dbc, "\tSG_ Subservice m1M : 8|8@1+ (1,0) [0|255] \"\" DriverGateway\n")
+		a.Contains(dbc, "\tSG_ Full : 0|8@1- (0.5,-10) [-74|53.5] \"\" DriverGateway\n")
+		a.Contains(dbc, "\nTP_ EngineSpeed vehicle/engine/speed\n")
+		a.Contains(dbc, `VAL_ 124 Status 0 "Off" 1 "Ready" 15 "Fault" ;`)
+		a.Contains(dbc, `VAL_ 2147483904 Full -1 "Invalid" ;`)
+		a.Contains(dbc, "\nCM_ \"Test network\";\nCM_ BU_ BMS \"Battery management system\";\nCM_ BO_ 123 \"Engine speed,\nsent every 10 ms\";\nCM_ SG_ 200 Service \"Selects the \\\"service\\\"\";\n")
+	})
+
+	t.Run("should only write SG_MUL_VAL_ when the indicator is not enough", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(roundTripConfig))
+		a.Nil(err)
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+
+		dbc := b.String()
+		a.Contains(dbc, "SG_MUL_VAL_ 200 Counter Subservice 1-1, 4-6;")
+		a.Contains(dbc, "SG_MUL_VAL_ 200 Cell Service 3-5;")
+		a.NotContains(dbc, "SG_MUL_VAL_ 200 Subservice")
+		a.NotContains(dbc, "SG_MUL_VAL_ 200 RawData")
+	})
+
+	t.Run("should write attributes, leaving out defaults", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(roundTripConfig))
+		a.Nil(err)
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+
+		dbc := b.String()
+		a.Contains(dbc, "\nBA_DEF_ \"BusType\" STRING;\nBA_DEF_ BU_ \"ILUsed\" ENUM \"No\",\"Yes\";\n")
+		a.Contains(dbc, "\nBA_DEF_DEF_ \"ILUsed\" 0;\n")
+		a.Contains(dbc, "\nBA_ \"BusType\" \"CAN FD\";\n")
+		a.Contains(dbc, "\nBA_ \"ILUsed\" BU_ BMS 1;\n")
+		a.Contains(dbc, "\nBA_ \"GenSigStartValue\" SG_ 123 OilTemperature -40.5;\n")
+		a.NotContains(dbc, "BA_ \"GenMsgCycleTime\" BO_ 200")
+		a.NotContains(dbc, "BA_ \"ILUsed\" BU_ Engine")
+	})
+
+	t.Run("should write the value type of IEEE signals", func(t *testing.T) {
+		a := assert.New(t)
+
+		config, err := Parse(strings.NewReader(roundTripConfig))
+		a.Nil(err)
+
+		var b strings.Builder
+		a.Nil(config.WriteDBC(&b))
+		a.Contains(b.String(), "\nSIG_VALTYPE_ 129 AccelX : 1;\nSIG_VALTYPE_ 130 Uptime : 2;\n")
+	})
+}